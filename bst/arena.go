@@ -0,0 +1,392 @@
+package bst
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"math"
+)
+
+type arenaNode[T any] struct {
+	value  T
+	left   int32
+	right  int32
+	parent int32
+	clr    color
+}
+
+// ArenaRBTree is a red-black tree that keeps all its nodes in a single slice and links them by index.
+// It is an opt-in alternative to RBTree for large trees of cheap-to-compare values like ints,
+// where lookups are dominated by pointer chasing rather than comparisons.
+//
+// Tradeoffs compared to RBTree:
+//   - Links are 4-byte indices and colors a single byte, so a node of an int takes 24 bytes
+//     instead of 64, and more of the tree fits in the CPU caches. Nodes are laid out in insertion order,
+//     not in tree order, so a lookup still jumps around the slice. The garbage collector also has
+//     far fewer pointers to scan. BenchmarkExists measures the effect on lookups.
+//   - Values are ordered by their natural order only. There is no custom comparator.
+//   - Nodes carry no augmentation, so there are no order statistics or cached heights.
+//   - Slots of deleted nodes are reused by later inserts, but the backing slice never shrinks.
+//   - Growing the backing slice copies it, so an individual Insert can take O(n) time,
+//     though inserts still take amortized O(log n) time.
+//   - It can hold at most math.MaxInt32 - 1 values.
+//
+// Since links are indices, the CLRS sentinel is used as is: index 0 is a black node standing for all leaves.
+type ArenaRBTree[T cmp.Ordered] struct {
+	// nodes[0] is the sentinel
+	nodes []arenaNode[T]
+	root  int32
+	// head of the list of free slots, chained through their right links
+	free int32
+	len  int
+}
+
+func NewArenaRBTree[T cmp.Ordered]() *ArenaRBTree[T] {
+	return &ArenaRBTree[T]{
+		nodes: make([]arenaNode[T], 1),
+	}
+}
+
+func (at *ArenaRBTree[T]) Len() int {
+	return at.len
+}
+
+// Insert a new node in the tree with the given value. Inserts even if the value already exists.
+func (at *ArenaRBTree[T]) Insert(val T) {
+	z := at.alloc(val)
+	nodes := at.nodes
+
+	var p int32 = 0
+	x := at.root
+
+	for x != 0 {
+		p = x
+		if val <= nodes[x].value {
+			x = nodes[x].left
+		} else {
+			x = nodes[x].right
+		}
+	}
+
+	nodes[z].parent = p
+	if p == 0 {
+		at.root = z
+	} else if val <= nodes[p].value {
+		nodes[p].left = z
+	} else {
+		nodes[p].right = z
+	}
+
+	at.len++
+	at.fixInsert(z)
+}
+
+// Returns true if there exists a node having the given value in the tree.
+func (at *ArenaRBTree[T]) Exists(val T) bool {
+	return at.findNode(val) != 0
+}
+
+// Deletes a node in the tree with the given value.
+// If there are multiple such nodes, any one of them might be deleted.
+// Returns an error wrapping ErrValueDoesNotExist if no such node is found.
+func (at *ArenaRBTree[T]) Delete(val T) error {
+	z := at.findNode(val)
+	if z == 0 {
+		return fmt.Errorf("delete %v: %w", val, ErrValueDoesNotExist)
+	}
+
+	nodes := at.nodes
+	y := z
+	ogColor := nodes[y].clr
+	var x int32
+
+	if nodes[z].left == 0 {
+		x = nodes[z].right
+		at.transplant(z, x)
+	} else if nodes[z].right == 0 {
+		x = nodes[z].left
+		at.transplant(z, x)
+	} else {
+		// substitute for z
+		y = at.getMin(nodes[z].right)
+		ogColor = nodes[y].clr
+		x = nodes[y].right
+
+		if nodes[y].parent == z {
+			// x might be the sentinel, whose parent is needed by fixDelete
+			nodes[x].parent = y
+		} else {
+			at.transplant(y, nodes[y].right)
+			nodes[y].right = nodes[z].right
+			nodes[nodes[y].right].parent = y
+		}
+
+		at.transplant(z, y)
+		nodes[y].left = nodes[z].left
+		nodes[nodes[y].left].parent = y
+		nodes[y].clr = nodes[z].clr
+	}
+
+	if ogColor == black {
+		at.fixDelete(x)
+	}
+
+	at.release(z)
+	at.len--
+	return nil
+}
+
+// Returns an iterator over all values in ascending order.
+func (at *ArenaRBTree[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if at.root == 0 {
+			return
+		}
+
+		for x := at.getMin(at.root); x != 0; x = at.successor(x) {
+			if !yield(at.nodes[x].value) {
+				return
+			}
+		}
+	}
+}
+
+// Returns the index of a free slot holding a new red node with the given value.
+func (at *ArenaRBTree[T]) alloc(val T) int32 {
+	if len(at.nodes) == 0 {
+		// zero value of the tree, add the sentinel
+		at.nodes = make([]arenaNode[T], 1)
+	}
+
+	nd := arenaNode[T]{
+		value: val,
+		clr:   red,
+	}
+
+	if at.free != 0 {
+		z := at.free
+		at.free = at.nodes[z].right
+		at.nodes[z] = nd
+		return z
+	}
+
+	if len(at.nodes) == math.MaxInt32 {
+		panic("goalds: ArenaRBTree is full")
+	}
+
+	at.nodes = append(at.nodes, nd)
+	return int32(len(at.nodes) - 1)
+}
+
+// Adds the slot z to the free list.
+func (at *ArenaRBTree[T]) release(z int32) {
+	// zero the value so that the slot doesn't keep anything alive
+	at.nodes[z] = arenaNode[T]{right: at.free}
+	at.free = z
+}
+
+func (at *ArenaRBTree[T]) findNode(val T) int32 {
+	nodes := at.nodes
+	x := at.root
+
+	for x != 0 {
+		v := nodes[x].value
+		if v == val {
+			return x
+		} else if v < val {
+			x = nodes[x].right
+		} else {
+			x = nodes[x].left
+		}
+	}
+
+	return 0
+}
+
+func (at *ArenaRBTree[T]) getMin(x int32) int32 {
+	for at.nodes[x].left != 0 {
+		x = at.nodes[x].left
+	}
+	return x
+}
+
+// Returns the node after x in inorder traversal, or 0 if x is the last node.
+func (at *ArenaRBTree[T]) successor(x int32) int32 {
+	nodes := at.nodes
+	if nodes[x].right != 0 {
+		return at.getMin(nodes[x].right)
+	}
+
+	p := nodes[x].parent
+	for p != 0 && x == nodes[p].right {
+		x = p
+		p = nodes[p].parent
+	}
+	return p
+}
+
+// Replaces the subtree rooted at u with the subtree rooted at v, which may be the sentinel.
+func (at *ArenaRBTree[T]) transplant(u, v int32) {
+	nodes := at.nodes
+	p := nodes[u].parent
+
+	if p == 0 {
+		at.root = v
+	} else if u == nodes[p].left {
+		nodes[p].left = v
+	} else {
+		nodes[p].right = v
+	}
+
+	// written even when v is the sentinel, which fixDelete relies on
+	nodes[v].parent = p
+}
+
+func (at *ArenaRBTree[T]) rotateLeft(x int32) {
+	nodes := at.nodes
+	y := nodes[x].right
+
+	nodes[x].right = nodes[y].left
+	if nodes[y].left != 0 {
+		nodes[nodes[y].left].parent = x
+	}
+
+	at.transplant(x, y)
+	nodes[y].left = x
+	nodes[x].parent = y
+}
+
+func (at *ArenaRBTree[T]) rotateRight(x int32) {
+	nodes := at.nodes
+	y := nodes[x].left
+
+	nodes[x].left = nodes[y].right
+	if nodes[y].right != 0 {
+		nodes[nodes[y].right].parent = x
+	}
+
+	at.transplant(x, y)
+	nodes[y].right = x
+	nodes[x].parent = y
+}
+
+// Same as RBTree.fixInsert, but with the sentinel instead of nil.
+func (at *ArenaRBTree[T]) fixInsert(z int32) {
+	nodes := at.nodes
+
+	for nodes[nodes[z].parent].clr == red {
+		p := nodes[z].parent
+		g := nodes[p].parent
+
+		if p == nodes[g].left {
+			psib := nodes[g].right
+
+			if nodes[psib].clr == red {
+				nodes[psib].clr = black
+				nodes[p].clr = black
+				nodes[g].clr = red
+				z = g
+			} else {
+				if z == nodes[p].right {
+					z = p
+					at.rotateLeft(z)
+				}
+
+				p = nodes[z].parent
+				g = nodes[p].parent
+				nodes[p].clr = black
+				nodes[g].clr = red
+				at.rotateRight(g)
+			}
+		} else {
+			psib := nodes[g].left
+
+			if nodes[psib].clr == red {
+				nodes[psib].clr = black
+				nodes[p].clr = black
+				nodes[g].clr = red
+				z = g
+			} else {
+				if z == nodes[p].left {
+					z = p
+					at.rotateRight(z)
+				}
+
+				p = nodes[z].parent
+				g = nodes[p].parent
+				nodes[p].clr = black
+				nodes[g].clr = red
+				at.rotateLeft(g)
+			}
+		}
+	}
+
+	nodes[at.root].clr = black
+}
+
+// Same as RBTree.fixDelete, but the sentinel holds the parent of x when x is a leaf.
+func (at *ArenaRBTree[T]) fixDelete(x int32) {
+	nodes := at.nodes
+
+	for x != at.root && nodes[x].clr == black {
+		p := nodes[x].parent
+
+		if x == nodes[p].left {
+			sib := nodes[p].right
+
+			if nodes[sib].clr == red {
+				nodes[sib].clr = black
+				nodes[p].clr = red
+				at.rotateLeft(p)
+				sib = nodes[p].right
+			}
+
+			if nodes[nodes[sib].left].clr == black && nodes[nodes[sib].right].clr == black {
+				nodes[sib].clr = red
+				x = p
+			} else {
+				if nodes[nodes[sib].right].clr == black {
+					nodes[nodes[sib].left].clr = black
+					nodes[sib].clr = red
+					at.rotateRight(sib)
+					sib = nodes[p].right
+				}
+
+				nodes[sib].clr = nodes[p].clr
+				nodes[p].clr = black
+				nodes[nodes[sib].right].clr = black
+				at.rotateLeft(p)
+				x = at.root
+			}
+		} else {
+			sib := nodes[p].left
+
+			if nodes[sib].clr == red {
+				nodes[sib].clr = black
+				nodes[p].clr = red
+				at.rotateRight(p)
+				sib = nodes[p].left
+			}
+
+			if nodes[nodes[sib].left].clr == black && nodes[nodes[sib].right].clr == black {
+				nodes[sib].clr = red
+				x = p
+			} else {
+				if nodes[nodes[sib].left].clr == black {
+					nodes[nodes[sib].right].clr = black
+					nodes[sib].clr = red
+					at.rotateLeft(sib)
+					sib = nodes[p].left
+				}
+
+				nodes[sib].clr = nodes[p].clr
+				nodes[p].clr = black
+				nodes[nodes[sib].left].clr = black
+				at.rotateRight(p)
+				x = at.root
+			}
+		}
+	}
+
+	nodes[x].clr = black
+}
@@ -0,0 +1,70 @@
+package bst
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestArenaRBTree(t *testing.T) {
+	r := rand.New(rand.NewSource(17))
+	at := NewArenaRBTree[int]()
+	rb := NewRBTree[int]()
+
+	for i := 0; i < 5000; i++ {
+		v := r.Intn(300)
+		if r.Intn(3) > 0 {
+			at.Insert(v)
+			rb.Insert(v)
+			continue
+		}
+
+		want := rb.Delete(v)
+		if got := at.Delete(v); (got == nil) != (want == nil) || (got != nil && !errors.Is(got, ErrValueDoesNotExist)) {
+			t.Fatalf("Delete(%d): got error %v, want %v", v, got, want)
+		}
+	}
+
+	if got, want := slices.Collect(at.All()), rb.GetValues(); !slices.Equal(got, want) || at.Len() != rb.Len() {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for v := -1; v < 301; v++ {
+		if at.Exists(v) != rb.Exists(v) {
+			t.Fatalf("Exists(%d) disagrees with RBTree", v)
+		}
+	}
+}
+
+// Compares lookups of present values in both trees, built from the same random ints.
+// Run with -bench=Exists -benchtime=2000000x to keep the setup of the large trees from dominating.
+func BenchmarkExists(b *testing.B) {
+	for _, n := range []int{1_000_000, 10_000_000} {
+		r := rand.New(rand.NewSource(1))
+		keys := make([]int, n)
+		for i := range keys {
+			keys[i] = r.Int()
+		}
+
+		// each tree is built inside its own b.Run, so that only one of them is alive at a time
+		b.Run(fmt.Sprintf("RBTree/%d", n), func(b *testing.B) {
+			rb := NewRBTree[int]()
+			rb.InsertAll(keys...)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				rb.Exists(keys[i%n])
+			}
+		})
+		b.Run(fmt.Sprintf("ArenaRBTree/%d", n), func(b *testing.B) {
+			at := NewArenaRBTree[int]()
+			for _, k := range keys {
+				at.Insert(k)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				at.Exists(keys[i%n])
+			}
+		})
+	}
+}
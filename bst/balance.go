@@ -0,0 +1,156 @@
+package bst
+
+// Balancing selects the strategy a tree uses to keep itself balanced after insertions and deletions.
+// All strategies keep the height of the tree in O(log n), and support the same operations.
+type Balancing int
+
+const (
+	// Red-black balancing, with the properties listed on RBTree. This is the default.
+	// Insertions and deletions perform at most 2 and 3 rotations respectively.
+	RedBlack Balancing = iota
+	// AVL balancing, where the heights of the two subtrees of every node differ by at most one.
+	// The tree is more rigidly balanced than a red-black tree, which makes lookups faster
+	// at the cost of more rotations on insertions and deletions. Node colors carry no meaning.
+	AVL
+)
+
+// balancer restores the balance of a tree after a node is linked or unlinked by the plain BST logic.
+// Both are called after the sizes and heights of all ancestors of the change are up to date,
+// and may only restructure the tree using rotations. A rotation only updates the two nodes it moves,
+// so the balancer must update their ancestors before returning.
+type balancer[T any] interface {
+	// Called after nd is linked as a new leaf, or as the root of an empty tree.
+	fixInsert(rb *RBTree[T], nd *node[T])
+	// Called after a node is unlinked. nd is the node that took the place of the removed one, and may be nil.
+	// p is the parent of nd, or nil if nd is the root. removedBlack tells whether the node removed
+	// from the position of nd was black.
+	fixDelete(rb *RBTree[T], nd, p *node[T], removedBlack bool)
+	// Returns true if linking a new leaf as a child of p would make fixInsert perform a rotation.
+	// p is nil for an empty tree.
+	wouldRotate(p *node[T]) bool
+}
+
+// Returns the balancer of the tree's balancing strategy.
+func (rb *RBTree[T]) balancer() balancer[T] {
+	if rb.balancing == AVL {
+		return avlBalancer[T]{}
+	}
+	return redBlackBalancer[T]{}
+}
+
+type redBlackBalancer[T any] struct{}
+
+// Every rotation of fixInsert is at an ancestor of nd, and leaves the rotated nodes above nd,
+// so updating the ancestors of nd after all rotations covers the ancestors of every rotation.
+func (redBlackBalancer[T]) fixInsert(rb *RBTree[T], nd *node[T]) {
+	before := rb.rotations
+	rb.fixInsert(nd)
+	if rb.rotations != before {
+		rb.updateUp(nd)
+	}
+}
+
+// Likewise, every rotation of fixDelete is at p or above, or at the sibling of a node on that path,
+// in which case it is followed by a rotation at the parent of that node, which moves the rotated nodes above p.
+func (redBlackBalancer[T]) fixDelete(rb *RBTree[T], nd, p *node[T], removedBlack bool) {
+	// removing a red node doesn't change any black counts
+	if !removedBlack {
+		return
+	}
+
+	before := rb.rotations
+	rb.fixDelete(nd, p)
+	if rb.rotations != before {
+		rb.updateUp(p)
+	}
+}
+
+// Replays the decisions of fixInsert: while the parent of the red node is red, a red uncle only
+// recolors and moves up to the grandparent, and a black uncle means a rotation.
+func (redBlackBalancer[T]) wouldRotate(p *node[T]) bool {
+	// p is the parent of the red node, which starts out as the new node
+	for p.color() == red {
+		// p is red, so it is not the root and has a parent
+		g := p.parent
+		psib := g.left
+		if p == g.left {
+			psib = g.right
+		}
+
+		if psib.color() == black {
+			return true
+		}
+
+		// recoloring makes g red, after which g becomes the red node
+		p = g.parent
+	}
+
+	return false
+}
+
+type avlBalancer[T any] struct{}
+
+func (avlBalancer[T]) fixInsert(rb *RBTree[T], nd *node[T]) {
+	rb.rebalanceAVL(nd.parent)
+}
+
+func (avlBalancer[T]) fixDelete(rb *RBTree[T], nd, p *node[T], removedBlack bool) {
+	rb.rebalanceAVL(p)
+}
+
+// Walks up from the parent of the new leaf, tracking the heights the ancestors would have,
+// until a node goes out of balance, or a height stays the same and nothing above can change.
+func (avlBalancer[T]) wouldRotate(p *node[T]) bool {
+	// height of the subtree of p containing the new leaf
+	childHt := 1
+	var child *node[T] = nil
+
+	for ; p != nil; child, p = p, p.parent {
+		lh, rh := p.left.height(), p.right.height()
+		if child == p.left {
+			// also covers a new left leaf, since both are nil then
+			lh = childHt
+		} else {
+			rh = childHt
+		}
+
+		if lh-rh > 1 || rh-lh > 1 {
+			return true
+		}
+
+		ht := max(lh, rh) + 1
+		if ht == p.ht {
+			return false
+		}
+		childHt = ht
+	}
+
+	return false
+}
+
+// Restores the AVL property at nd and all its ancestors, whose subtrees might be out of balance by one level.
+// Since the walk goes all the way up, it also updates the heights above every rotation, in O(1) per node.
+func (rb *RBTree[T]) rebalanceAVL(nd *node[T]) {
+	for nd != nil {
+		// a rotation below might have changed the height of nd, and its children are up to date
+		rb.update(nd)
+
+		// nd moves down when rotated, so remember where to continue
+		p := nd.parent
+		bf := nd.left.height() - nd.right.height()
+
+		if bf > 1 {
+			if nd.left.left.height() < nd.left.right.height() {
+				rb.rotateLeft(nd.left)
+			}
+			rb.rotateRight(nd)
+		} else if bf < -1 {
+			if nd.right.right.height() < nd.right.left.height() {
+				rb.rotateRight(nd.right)
+			}
+			rb.rotateLeft(nd)
+		}
+
+		nd = p
+	}
+}
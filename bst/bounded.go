@@ -0,0 +1,68 @@
+package bst
+
+import "cmp"
+
+// BoundedRBTree is a tree that holds at most a fixed number of values, keeping the largest ones seen so far.
+// Inserting into a full tree evicts the smallest value, which may be the one just inserted.
+// Insert takes O(log n) time, and Len, Capacity, Min and Max take O(1).
+type BoundedRBTree[T any] struct {
+	tree     *RBTree[T]
+	capacity int
+	onEvict  func(T)
+}
+
+// Returns an empty tree holding at most capacity values, with the given options.
+// A negative capacity is treated as 0.
+func NewBoundedRBTree[T cmp.Ordered](capacity int, opts ...Option) *BoundedRBTree[T] {
+	return &BoundedRBTree[T]{
+		tree:     NewRBTree[T](opts...),
+		capacity: max(capacity, 0),
+	}
+}
+
+func (b *BoundedRBTree[T]) Len() int {
+	return b.tree.Len()
+}
+
+func (b *BoundedRBTree[T]) Capacity() int {
+	return b.capacity
+}
+
+// Sets fn to be called with each value evicted by Insert, replacing any previous callback. Pass nil to remove it.
+// Eviction happens after the new value is inserted, so when the tree is full, fn sees the smallest value
+// among the old ones and the new one, which is the new value itself if it is not greater than the old minimum.
+// fn is called synchronously inside Insert, and must not modify the tree.
+func (b *BoundedRBTree[T]) OnEvict(fn func(T)) {
+	b.onEvict = fn
+}
+
+// Inserts val, evicting the smallest value if the tree is over capacity. Returns true if val is still stored.
+// Ties and duplicates are handled like in RBTree.InsertIfGreaterThanMin.
+func (b *BoundedRBTree[T]) Insert(val T) bool {
+	return b.tree.insertBounded(val, b.capacity, b.onEvict)
+}
+
+// Removes one node with the given value. Returns ErrValueDoesNotExist if there is none.
+func (b *BoundedRBTree[T]) Delete(val T) error {
+	return b.tree.Delete(val)
+}
+
+// Returns true if val is stored in the tree.
+func (b *BoundedRBTree[T]) Exists(val T) bool {
+	return b.tree.Exists(val)
+}
+
+// Returns the smallest value, or false if the tree is empty.
+func (b *BoundedRBTree[T]) Min() (T, bool) {
+	return b.tree.Min()
+}
+
+// Returns the largest value, or false if the tree is empty.
+func (b *BoundedRBTree[T]) Max() (T, bool) {
+	return b.tree.Max()
+}
+
+// Returns the stored values in ascending order.
+func (b *BoundedRBTree[T]) GetValues() []T {
+	return b.tree.GetValues()
+}
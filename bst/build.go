@@ -0,0 +1,376 @@
+package bst
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/bits"
+	"slices"
+)
+
+// Builds a balanced subtree from sorted values and returns its root, or nil if there are no values.
+// Since the halves of every subtree differ in size by at most one, all nil leaves are at depth
+// maxDepth or maxDepth+1, where maxDepth is the depth of the deepest node.
+// Coloring the nodes at maxDepth red, and the rest black, makes every path contain the same number of black nodes.
+func buildBalanced[T any](sorted []T) *node[T] {
+	nodes := make([]*node[T], len(sorted))
+	for i, v := range sorted {
+		nodes[i] = &node[T]{value: v}
+	}
+	return linkBalanced(nodes)
+}
+
+// Same as buildBalanced, but reuses the given nodes, which must be in inorder.
+// Their links, colors, sizes and heights are overwritten.
+func linkBalanced[T any](nodes []*node[T]) *node[T] {
+	n := len(nodes)
+	if n == 0 {
+		return nil
+	}
+
+	maxDepth := bits.Len(uint(n)) - 1
+	root := linkSubtree(nodes, 0, maxDepth)
+	root.parent = nil
+	// a lone root must stay black
+	root.clr = black
+	return root
+}
+
+func linkSubtree[T any](nodes []*node[T], depth, redDepth int) *node[T] {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	mid := len(nodes) / 2
+	nd := nodes[mid]
+
+	nd.clr = black
+	if depth == redDepth {
+		nd.clr = red
+	}
+
+	nd.left = linkSubtree(nodes[:mid], depth+1, redDepth)
+	if nd.left != nil {
+		nd.left.parent = nd
+	}
+
+	nd.right = linkSubtree(nodes[mid+1:], depth+1, redDepth)
+	if nd.right != nil {
+		nd.right.parent = nd
+	}
+
+	nd.update()
+
+	return nd
+}
+
+// Returns a tree containing the given values, built in O(n log n) time for sorting, and O(n) for building.
+// The tree has the balanced shape of minimum height described in Rebalance. The input slice is not modified.
+func NewRBTreeFromSlice[T cmp.Ordered](vals []T) *RBTree[T] {
+	sorted := slices.Clone(vals)
+	slices.Sort(sorted)
+
+	rb := NewRBTree[T]()
+	rb.setRoot(buildBalanced(sorted), len(sorted))
+	return rb
+}
+
+// Same as NewRBTreeFromSlice, but keeps only one copy of each value, and returns a tree that rejects duplicates,
+// so that it stays a set. Duplicates are dropped after sorting, so the build still takes O(n) time.
+// The input slice is not modified.
+func NewRBSetFromSlice[T cmp.Ordered](vals []T) *RBTree[T] {
+	sorted := slices.Clone(vals)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	rb := NewRBTree[T](WithDuplicatePolicy(RejectDuplicates))
+	rb.setRoot(buildBalanced(sorted), len(sorted))
+	return rb
+}
+
+// Returns the number of black nodes on any path from nd down to a leaf, including nd itself.
+func (nd *node[T]) blackHeight() int {
+	h := 0
+	for ; nd != nil; nd = nd.left {
+		if nd.clr == black {
+			h++
+		}
+	}
+	return h
+}
+
+// Inserts all the given values in the tree.
+func (rb *RBTree[T]) InsertAll(vals ...T) {
+	for _, v := range vals {
+		rb.Insert(v)
+	}
+}
+
+// How many values bulk operations process between checks for context cancellation.
+const ctxCheckInterval = 1024
+
+// Same as InsertAll, but stops early if ctx is cancelled, returning ctx.Err().
+// The context is checked before the first insertion and then periodically.
+// On cancellation, the values inserted so far stay in the tree, which remains valid with an accurate Len.
+func (rb *RBTree[T]) InsertAllCtx(ctx context.Context, vals ...T) error {
+	for i, v := range vals {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		rb.Insert(v)
+	}
+	return nil
+}
+
+// Inserts values from sortedGreater, which must be in ascending order with every value >= the maximum of the tree.
+// Instead of inserting one by one, the values are built into a balanced tree, which is joined to this one.
+// This takes O(m + log n) time, where m is the number of values.
+// If the precondition does not hold, or the tree has options that joining can't honor, like a duplicate policy,
+// balancing other than red-black or insertion order, it falls back to InsertAll.
+func (rb *RBTree[T]) JoinSorted(sortedGreater []T) {
+	if len(sortedGreater) == 0 {
+		return
+	}
+
+	// joining can't detect duplicates, so let Insert apply the duplicate policy unless duplicates are kept
+	if rb.dups != KeepDuplicates || rb.balancing != RedBlack || rb.insertionOrder || !slices.IsSortedFunc(sortedGreater, rb.cmp) ||
+		(rb.root != nil && rb.cmp(sortedGreater[0], rb.maxNode().value) < 0) {
+		rb.InsertAll(sortedGreater...)
+		return
+	}
+
+	rest := sortedGreater[1:]
+	greater := &RBTree[T]{
+		cmp: rb.cmp,
+	}
+	greater.setRoot(buildBalanced(rest), len(rest))
+	rb.join(sortedGreater[0], greater)
+
+	for _, v := range rest {
+		rb.notify(Inserted, v)
+	}
+}
+
+// Joins pivot and all nodes of greater into the tree.
+// All values in the tree must be <= pivot, and pivot must be <= all values in greater.
+// The nodes of greater are moved into this tree and greater is left empty.
+// Takes O(log n) time.
+func (rb *RBTree[T]) join(pivot T, greater *RBTree[T]) {
+	if greater.root == nil {
+		rb.Insert(pivot)
+		return
+	}
+
+	if rb.root == nil {
+		rb.setRoot(greater.root, greater.len)
+		greater.setRoot(nil, 0)
+		// pivot is the new minimum, and Insert puts equal values on the left
+		rb.Insert(pivot)
+		return
+	}
+
+	lessBH := rb.root.blackHeight()
+	greaterBH := greater.root.blackHeight()
+
+	k := &node[T]{
+		value: pivot,
+		clr:   red,
+	}
+
+	if lessBH >= greaterBH {
+		// Find the black node c on the right spine of this tree having the same black height as greater.
+		// Then k replaces c, with c and greater as its left and right subtrees.
+		c, h := rb.root, lessBH
+		for c.clr == red || h > greaterBH {
+			if c.clr == black {
+				h--
+			}
+			c = c.right
+		}
+
+		rb.replace(c, k)
+		k.left, k.right = c, greater.root
+	} else {
+		// mirror of the above, on the left spine of greater
+		c, h := greater.root, greaterBH
+		for c.clr == red || h > lessBH {
+			if c.clr == black {
+				h--
+			}
+			c = c.left
+		}
+
+		greater.replace(c, k)
+		k.left, k.right = rb.root, c
+		rb.root = greater.root
+	}
+
+	k.left.parent = k
+	k.right.parent = k
+	rb.updateUp(k)
+
+	// pivot is neither the minimum nor the maximum, since both trees are non-empty
+	rb.len += greater.len + 1
+	rb.gen++
+	rb.maxNd = greater.maxNd
+	greater.setRoot(nil, 0)
+
+	// k might be the root, or might have a red parent. join is only used for red-black trees,
+	// and the balancer also updates the heights above the rotations.
+	redBlackBalancer[T]{}.fixInsert(rb, k)
+	rb.debugValidate()
+	rb.notify(Inserted, pivot)
+}
+
+// Inserts a value like Insert, but without restoring the red-black properties afterwards.
+// This is meant for bulk loading: after a batch of such insertions, call Rebalance once,
+// which restores the properties over the whole tree in O(n) instead of fixing up after every insertion.
+// The tree is not a valid red-black tree until Rebalance is called. Calling any other method
+// in between is undefined: queries still see every value, but may take O(n) time on a degenerate shape.
+func (rb *RBTree[T]) InsertNoFixup(val T) {
+	p, existing := rb.locate(val)
+	if existing != nil {
+		rb.resolveDuplicate(existing, val)
+		return
+	}
+
+	rb.link(p, val)
+	rb.notify(Inserted, val)
+}
+
+// Restructures the tree into a balanced shape of minimum height, restoring all red-black properties.
+// The resulting shape and colors depend only on the values in the tree, not on the history of operations,
+// and are the same as those of NewRBTreeFromSlice(rb.GetValues()). Contents and Len are unchanged.
+// The existing nodes are relinked in place rather than reallocated. Takes O(n) time.
+func (rb *RBTree[T]) Rebalance() {
+	nodes := make([]*node[T], 0, rb.len)
+	for nd := rb.minNode(); nd != nil; nd = nd.successor() {
+		nodes = append(nodes, nd)
+	}
+
+	rb.root = linkBalanced(nodes)
+	rb.gen++
+	rb.debugValidate()
+}
+
+// Returns a tree containing the values of both lo and hi, where every value in lo must be less than every value in hi.
+// Instead of merging the values, hi is attached along the right spine of lo, or lo along the left spine of hi,
+// and the colors are fixed up. This takes O(log n) time.
+// The nodes are moved, not copied: lo and hi are left empty, and no change events are reported.
+// The result is ordered like lo, and takes over its duplicate policy, balancing, insertion order and observer.
+// Both trees must use the same ordering. With insertion order, the nodes keep their sequence numbers,
+// which are 0 for the nodes of hi if hi didn't keep insertion order, and new values are stamped after all of them.
+// Joining relies on the red-black properties, so if either tree uses another balancing strategy,
+// the nodes of both are instead relinked into a balanced tree in O(n) time.
+// Returns an error wrapping ErrInvalidRange if the maximum of lo is not less than the minimum of hi,
+// in which case both trees are left unchanged.
+func Concat[T any](lo, hi *RBTree[T]) (*RBTree[T], error) {
+	if lo.root != nil && hi.root != nil {
+		loMax, hiMin := lo.maxNode().value, hi.minNode().value
+		if lo.cmp(loMax, hiMin) >= 0 {
+			return nil, fmt.Errorf("concat: max %v of lo is not less than min %v of hi: %w", loMax, hiMin, ErrInvalidRange)
+		}
+	}
+
+	res := &RBTree[T]{
+		cmp:            lo.cmp,
+		dups:           lo.dups,
+		balancing:      lo.balancing,
+		insertionOrder: lo.insertionOrder,
+		// equal values can't be in both trees, so the stamps of both are consistent
+		seq: max(lo.seq, hi.seq),
+	}
+
+	switch {
+	case lo.balancing != RedBlack || hi.balancing != RedBlack:
+		nodes := make([]*node[T], 0, lo.len+hi.len)
+		for _, t := range [2]*RBTree[T]{lo, hi} {
+			for nd := t.minNode(); nd != nil; nd = nd.successor() {
+				nodes = append(nodes, nd)
+			}
+		}
+		res.setRoot(linkBalanced(nodes), len(nodes))
+
+	case lo.root == nil || hi.root == nil:
+		// nothing to join, so the other tree is moved as is
+		if lo.root != nil {
+			res.setRoot(lo.root, lo.len)
+		} else {
+			res.setRoot(hi.root, hi.len)
+		}
+
+	default:
+		loMax := lo.maxNode()
+		res.setRoot(lo.root, lo.len)
+		greater := &RBTree[T]{
+			cmp: lo.cmp,
+		}
+		greater.setRoot(hi.root, hi.len)
+
+		// the minimum of hi becomes the pivot of the join
+		pivot := greater.minNode()
+		greater.deleteNode(pivot)
+		res.join(pivot.value, greater)
+
+		// join links a new node for the pivot, right after the old maximum of lo, which takes over its stamp
+		loMax.successor().seq = pivot.seq
+	}
+
+	lo.setRoot(nil, 0)
+	hi.setRoot(nil, 0)
+	// set only now, so that the insertion of the pivot is not reported
+	res.onChange = lo.onChange
+	res.debugValidate()
+	return res, nil
+}
+
+// Deletes one node for each of the given values, which must be in ascending order. Values that don't exist are ignored.
+// Returns the number of nodes deleted.
+//
+// Deleting m values one by one takes O(m log n) time. When m is large compared to n/log n,
+// it is cheaper to walk the tree once, merging it with the values to find the survivors,
+// and to rebuild the survivors into a balanced tree, which takes O(n + m) time.
+// The cheaper strategy is chosen based on m and n. Rebuilding reuses the surviving nodes,
+// and gives the tree the shape described in Rebalance.
+// If the values are not sorted, they are deleted one by one.
+func (rb *RBTree[T]) DeleteSorted(sorted []T) int {
+	n, m := rb.len, len(sorted)
+
+	if m*bits.Len(uint(n)) <= n || !slices.IsSortedFunc(sorted, rb.cmp) {
+		count := 0
+		for _, v := range sorted {
+			if nd := rb.findNode(v); nd != nil {
+				rb.deleteNode(nd)
+				count++
+			}
+		}
+		return count
+	}
+
+	survivors := make([]*node[T], 0, n)
+	var deleted []T
+	i := 0
+
+	for nd := rb.minNode(); nd != nil; nd = nd.successor() {
+		for i < m && rb.cmp(sorted[i], nd.value) < 0 {
+			i++
+		}
+
+		if i < m && rb.cmp(sorted[i], nd.value) == 0 {
+			deleted = append(deleted, nd.value)
+			i++
+		} else {
+			survivors = append(survivors, nd)
+		}
+	}
+
+	rb.setRoot(linkBalanced(survivors), len(survivors))
+	rb.debugValidate()
+
+	for _, v := range deleted {
+		rb.notify(Deleted, v)
+	}
+	return len(deleted)
+}
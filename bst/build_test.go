@@ -0,0 +1,219 @@
+package bst
+
+import (
+	"context"
+	"errors"
+	"math/bits"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestNewRBTreeFromSlice(t *testing.T) {
+	for n := 0; n < 70; n++ {
+		vals := rand.New(rand.NewSource(int64(n))).Perm(n)
+		rb := NewRBTreeFromSlice(vals)
+
+		want := slices.Sorted(slices.Values(vals))
+		checkValues(t, rb, want)
+		if rb.Height() != bits.Len(uint(n)) {
+			t.Fatalf("%d values built into a tree of height %d", n, rb.Height())
+		}
+	}
+}
+
+func TestNewRBSetFromSlice(t *testing.T) {
+	rb := NewRBSetFromSlice([]int{3, 1, 3, 2, 1})
+	checkValues(t, rb, []int{1, 2, 3})
+
+	rb.Insert(2)
+	checkValues(t, rb, []int{1, 2, 3})
+}
+
+func TestJoinSorted(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+
+	for round := 0; round < 200; round++ {
+		rb := NewRBTree[int]()
+		for i := r.Intn(60); i > 0; i-- {
+			rb.Insert(r.Intn(100))
+		}
+
+		add := make([]int, r.Intn(80))
+		// some batches overlap the tree, or are unsorted, which takes the fallback
+		base := 100
+		if round%5 == 0 {
+			base = 50
+		}
+		for i := range add {
+			add[i] = base + r.Intn(200)
+		}
+		if round%3 > 0 {
+			slices.Sort(add)
+		}
+
+		want := slices.Sorted(slices.Values(append(rb.GetValues(), add...)))
+		rb.JoinSorted(add)
+		checkValues(t, rb, want)
+	}
+}
+
+func TestConcat(t *testing.T) {
+	type pair struct {
+		value int
+		seq   uint64
+	}
+	pairs := func(rb *RBTree[int]) []pair {
+		var res []pair
+		for v, seq := range rb.AllWithSeq() {
+			res = append(res, pair{v, seq})
+		}
+		return res
+	}
+
+	for _, cfg := range treeConfigs {
+		t.Run(cfg.name, func(t *testing.T) {
+			for n := 0; n < 40; n++ {
+				for m := 0; m < 40; m += 3 {
+					lo, hi := NewRBTree[int](cfg.opts...), NewRBTree[int](cfg.opts...)
+					var want []int
+					for i := 0; i < n; i++ {
+						lo.Insert(i / 2)
+						want = append(want, i/2)
+					}
+					for i := 0; i < m; i++ {
+						hi.Insert(100 + i/2)
+						want = append(want, 100+i/2)
+					}
+					wantPairs := append(pairs(lo), pairs(hi)...)
+
+					res, err := Concat(lo, hi)
+					if err != nil {
+						t.Fatal(err)
+					}
+					checkValues(t, res, want)
+					if got := pairs(res); !slices.Equal(got, wantPairs) {
+						t.Fatalf("Concat changed sequence numbers: got %v, want %v", got, wantPairs)
+					}
+					if lo.Len() != 0 || hi.Len() != 0 {
+						t.Fatal("Concat left nodes in its inputs")
+					}
+				}
+			}
+		})
+	}
+
+	lo, hi := NewRBTreeFromSlice([]int{1, 5}), NewRBTreeFromSlice([]int{5, 9})
+	if _, err := Concat(lo, hi); !errors.Is(err, ErrInvalidRange) {
+		t.Fatalf("overlapping trees: got error %v", err)
+	}
+	checkValues(t, lo, []int{1, 5})
+	checkValues(t, hi, []int{5, 9})
+}
+
+func TestConcatOptions(t *testing.T) {
+	// a comparator over a type that is not cmp.Ordered
+	type record struct {
+		key  int
+		name string
+	}
+	byKey := func(a, b record) int { return a.key - b.key }
+
+	lo := NewRBTreeFunc(byKey, WithInsertionOrder())
+	hi := NewRBTreeFunc(byKey, WithInsertionOrder())
+	lo.InsertAll(record{1, "a"}, record{2, "b"})
+	hi.InsertAll(record{3, "c"}, record{3, "d"}, record{4, "e"}, record{4, "f"}, record{4, "g"})
+
+	var events []ChangeEvent[record]
+	lo.OnChange(func(e ChangeEvent[record]) {
+		events = append(events, e)
+	})
+
+	res, err := Concat(lo, hi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("Concat reported %d change events", len(events))
+	}
+
+	// a new equal value goes after all existing ones, and is reported to the observer of lo
+	res.Insert(record{3, "h"})
+	checkValid(t, res)
+	var names []string
+	for v := range res.All() {
+		names = append(names, v.name)
+	}
+	if want := []string{"a", "b", "c", "d", "h", "e", "f", "g"}; !slices.Equal(names, want) {
+		t.Fatalf("got %v, want %v", names, want)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d change events after inserting, want 1", len(events))
+	}
+}
+
+func TestDeleteSorted(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+
+	for _, m := range []int{0, 3, 50, 400} {
+		rb := NewRBTree[int]()
+		var want []int
+		for i := 0; i < 300; i++ {
+			v := r.Intn(100)
+			rb.Insert(v)
+			want = append(want, v)
+		}
+		slices.Sort(want)
+
+		del := make([]int, m)
+		for i := range del {
+			del[i] = r.Intn(120)
+		}
+		slices.Sort(del)
+
+		count := 0
+		for _, v := range del {
+			if i := slices.Index(want, v); i >= 0 {
+				want = slices.Delete(want, i, i+1)
+				count++
+			}
+		}
+
+		if got := rb.DeleteSorted(del); got != count {
+			t.Fatalf("deleting %d values: got count %d, want %d", m, got, count)
+		}
+		checkValues(t, rb, want)
+	}
+}
+
+func TestInsertNoFixupRebalance(t *testing.T) {
+	rb := NewRBTree[int]()
+	var want []int
+	for i := 0; i < 1000; i++ {
+		rb.InsertNoFixup(i % 37)
+		want = append(want, i%37)
+	}
+	slices.Sort(want)
+
+	rb.Rebalance()
+	checkValues(t, rb, want)
+	if got := NewRBTreeFromSlice(want).Structure(); !reflect.DeepEqual(rb.Structure(), got) {
+		t.Fatal("Rebalance built a different shape than NewRBTreeFromSlice")
+	}
+}
+
+func TestInsertAllCtx(t *testing.T) {
+	rb := NewRBTree[int]()
+	if err := rb.InsertAllCtx(context.Background(), 3, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	checkValues(t, rb, []int{1, 2, 3})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := rb.InsertAllCtx(ctx, 4, 5); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want context.Canceled", err)
+	}
+	checkValues(t, rb, []int{1, 2, 3})
+}
@@ -0,0 +1,353 @@
+package bst
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestRBMap(t *testing.T) {
+	m := NewRBMapFromPairs([]struct {
+		Key int
+		Val string
+	}{{3, "a"}, {1, "b"}, {3, "c"}, {2, "d"}})
+	checkValid(t, m.tree)
+
+	if got, _ := m.Get(3); got != "c" {
+		t.Fatalf("the last pair with a key should win: got %q", got)
+	}
+
+	m.Put(0, "z")
+	m.Put(3, "q")
+	if err := m.Delete(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Delete(7); !errors.Is(err, ErrKeyDoesNotExist) {
+		t.Fatalf("deleting a missing key: got error %v", err)
+	}
+
+	if want := []int{0, 1, 3}; !slices.Equal(m.Keys(), want) || m.Len() != 3 {
+		t.Fatalf("got keys %v, want %v", m.Keys(), want)
+	}
+	if got, _ := m.Get(3); got != "q" || m.Has(2) {
+		t.Fatal("Put did not overwrite, or Delete did not remove")
+	}
+}
+
+func TestRBMapAllBackward(t *testing.T) {
+	m := NewRBMap[int, string]()
+	m.Put(3, "c")
+	m.Put(1, "a")
+	m.Put(2, "b")
+
+	var keys []int
+	var vals []string
+	for k, v := range m.Backward() {
+		keys = append(keys, k)
+		vals = append(vals, v)
+	}
+	if !slices.Equal(keys, []int{3, 2, 1}) || !slices.Equal(vals, []string{"c", "b", "a"}) {
+		t.Fatalf("Backward: got %v, %v", keys, vals)
+	}
+
+	keys = nil
+	for k := range m.All() {
+		keys = append(keys, k)
+		if k == 2 {
+			break
+		}
+	}
+	if !slices.Equal(keys, []int{1, 2}) {
+		t.Fatalf("All stopping early: got %v", keys)
+	}
+
+	for k, v := range NewRBMap[int, string]().Backward() {
+		t.Fatalf("Backward on an empty map yielded %d, %q", k, v)
+	}
+}
+
+func TestRBMapGetOrPut(t *testing.T) {
+	m := NewRBMap[int, string]()
+	m.Put(1, "b")
+
+	if actual, loaded := m.GetOrPut(1, "x"); !loaded || actual != "b" {
+		t.Fatalf("GetOrPut on an existing key: got %q, %t", actual, loaded)
+	}
+	if actual, loaded := m.GetOrPut(5, "x"); loaded || actual != "x" {
+		t.Fatalf("GetOrPut on a new key: got %q, %t", actual, loaded)
+	}
+	if got, _ := m.Get(5); got != "x" || m.Len() != 2 {
+		t.Fatalf("GetOrPut did not store the new key: got %q, Len %d", got, m.Len())
+	}
+}
+
+func TestRBMapFloorEntry(t *testing.T) {
+	m := NewRBMap[int, string]()
+	m.Put(1, "b")
+	m.Put(3, "q")
+
+	if k, v, ok := m.FloorEntry(4); !ok || k != 3 || v != "q" {
+		t.Fatalf("FloorEntry(4): got %d, %q, %t", k, v, ok)
+	}
+	if k, v, ok := m.FloorEntry(1); !ok || k != 1 || v != "b" {
+		t.Fatalf("FloorEntry(1): got %d, %q, %t", k, v, ok)
+	}
+	if k, _, ok := m.FloorEntry(0); ok {
+		t.Fatalf("FloorEntry(0): got %d below the smallest key", k)
+	}
+}
+
+func TestRBMapCeilingEntry(t *testing.T) {
+	m := NewRBMap[int, string]()
+	if _, _, ok := m.CeilingEntry(1); ok {
+		t.Fatal("CeilingEntry on an empty map")
+	}
+
+	m.Put(1, "b")
+	m.Put(3, "q")
+	if k, v, ok := m.CeilingEntry(2); !ok || k != 3 || v != "q" {
+		t.Fatalf("CeilingEntry(2): got %d, %q, %t", k, v, ok)
+	}
+	if k, v, ok := m.CeilingEntry(1); !ok || k != 1 || v != "b" {
+		t.Fatalf("CeilingEntry(1): got %d, %q, %t", k, v, ok)
+	}
+	if k, _, ok := m.CeilingEntry(4); ok {
+		t.Fatalf("CeilingEntry(4): got %d above the largest key", k)
+	}
+}
+
+func TestRBMapMinMaxEntry(t *testing.T) {
+	m := NewRBMap[int, string]()
+	if _, _, ok := m.MinEntry(); ok {
+		t.Fatal("MinEntry on an empty map")
+	}
+	if _, _, ok := m.MaxEntry(); ok {
+		t.Fatal("MaxEntry on an empty map")
+	}
+
+	m.Put(3, "q")
+	m.Put(5, "x")
+	m.Put(1, "b")
+
+	if k, v, ok := m.MinEntry(); !ok || k != 1 || v != "b" {
+		t.Fatalf("MinEntry: got %d, %q, %t", k, v, ok)
+	}
+	if k, v, ok := m.MaxEntry(); !ok || k != 5 || v != "x" {
+		t.Fatalf("MaxEntry: got %d, %q, %t", k, v, ok)
+	}
+}
+
+func TestRBMapBoundEntries(t *testing.T) {
+	m := NewRBMap[int, string]()
+	m.Put(1, "b")
+	m.Put(3, "q")
+	m.Put(5, "x")
+
+	if k, _, ok := m.UpperBoundEntry(3); !ok || k != 5 {
+		t.Fatalf("UpperBoundEntry(3): got %d, %t", k, ok)
+	}
+	if k, _, ok := m.LowerBoundEntry(3); !ok || k != 3 {
+		t.Fatalf("LowerBoundEntry(3): got %d, %t", k, ok)
+	}
+}
+
+func TestRBTreeFromMap(t *testing.T) {
+	src := map[string]int{"b": 2, "a": 1, "c": 3}
+	m := NewRBTreeFromMap(src)
+
+	if !maps.Equal(maps.Collect(m.All()), src) {
+		t.Fatalf("got %v, want %v", maps.Collect(m.All()), src)
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(m.Keys(), want) {
+		t.Fatalf("got keys %v, want %v", m.Keys(), want)
+	}
+}
+
+func TestRBMultiset(t *testing.T) {
+	ms := NewRBMultiset[string]()
+	ms.Add("b", 2)
+	ms.Add("a", 1)
+	ms.Add("b", 3)
+	ms.Add("c", 0)
+
+	if ms.Len() != 6 || ms.DistinctLen() != 2 || ms.Count("b") != 5 {
+		t.Fatalf("got Len %d, DistinctLen %d, Count(b) %d", ms.Len(), ms.DistinctLen(), ms.Count("b"))
+	}
+
+	if got := ms.Remove("b", 10); got != 5 || ms.Count("b") != 0 || ms.DistinctLen() != 1 {
+		t.Fatalf("removing more copies than exist: removed %d", got)
+	}
+	if want := []string{"a"}; !slices.Equal(slices.Collect(ms.Expanded()), want) || ms.Len() != 1 {
+		t.Fatalf("got %v, want %v", slices.Collect(ms.Expanded()), want)
+	}
+	checkValid(t, ms.tree)
+}
+
+func TestRBMultisetSetCount(t *testing.T) {
+	ms := NewRBMultiset[string]()
+	ms.Add("a", 1)
+	ms.Add("b", 3)
+
+	ms.SetCount("d", 2)
+	ms.SetCount("b", 1)
+	ms.SetCount("a", -1)
+	if want := []string{"b", "d", "d"}; !slices.Equal(slices.Collect(ms.Expanded()), want) || ms.Len() != 3 {
+		t.Fatalf("got %v, want %v", slices.Collect(ms.Expanded()), want)
+	}
+	checkValid(t, ms.tree)
+}
+
+func TestRBMultisetAddAll(t *testing.T) {
+	ms := NewRBMultiset[string]()
+	ms.Add("d", 2)
+
+	other := NewRBMultiset[string]()
+	other.Add("a", 1)
+	other.Add("d", 3)
+	ms.AddAll(other)
+	if got := maps.Collect(ms.All()); !maps.Equal(got, map[string]int{"a": 1, "d": 5}) || ms.Len() != 6 {
+		t.Fatalf("AddAll: got %v", got)
+	}
+	if other.Len() != 4 {
+		t.Fatalf("AddAll modified its argument: got Len %d", other.Len())
+	}
+	checkValid(t, ms.tree)
+}
+
+func TestBoundedRBTree(t *testing.T) {
+	b := NewBoundedRBTree[int](3)
+	var evicted []int
+	b.OnEvict(func(v int) {
+		evicted = append(evicted, v)
+	})
+
+	stored := make([]bool, 0)
+	for _, v := range []int{5, 1, 7, 3, 9, 2, 8} {
+		stored = append(stored, b.Insert(v))
+	}
+
+	if want := []bool{true, true, true, true, true, false, true}; !slices.Equal(stored, want) {
+		t.Fatalf("got stored %v, want %v", stored, want)
+	}
+	if want := []int{7, 8, 9}; !slices.Equal(b.GetValues(), want) || b.Len() != b.Capacity() {
+		t.Fatalf("got values %v, want %v", b.GetValues(), want)
+	}
+	// the new value is inserted before the eviction, so 2 evicts itself
+	if want := []int{1, 3, 2, 5}; !slices.Equal(evicted, want) {
+		t.Fatalf("got evicted %v, want %v", evicted, want)
+	}
+}
+
+func TestBoundedRBTreeDelete(t *testing.T) {
+	b := NewBoundedRBTree[int](3)
+	if _, ok := b.Min(); ok {
+		t.Fatal("Min on an empty tree")
+	}
+	if _, ok := b.Max(); ok {
+		t.Fatal("Max on an empty tree")
+	}
+
+	var evicted []int
+	b.OnEvict(func(v int) {
+		evicted = append(evicted, v)
+	})
+	for _, v := range []int{5, 7, 9} {
+		b.Insert(v)
+	}
+	if lo, _ := b.Min(); lo != 5 {
+		t.Fatalf("Min: got %d", lo)
+	}
+	if hi, _ := b.Max(); hi != 9 {
+		t.Fatalf("Max: got %d", hi)
+	}
+
+	if err := b.Delete(7); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Delete(7); !errors.Is(err, ErrValueDoesNotExist) {
+		t.Fatalf("deleting a missing value: got error %v", err)
+	}
+	if b.Exists(7) || !b.Exists(5) {
+		t.Fatal("Exists disagrees with the deletion")
+	}
+
+	// the deletion freed a slot, so even a value below the minimum is stored without evicting anything
+	if !b.Insert(1) || len(evicted) != 0 {
+		t.Fatalf("got evicted %v after inserting into a freed slot", evicted)
+	}
+	if want := []int{1, 5, 9}; !slices.Equal(b.GetValues(), want) {
+		t.Fatalf("got values %v, want %v", b.GetValues(), want)
+	}
+
+	for _, v := range []int{1, 5, 9} {
+		if err := b.Delete(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := b.Min(); ok || b.Len() != 0 {
+		t.Fatal("Min after deleting every value")
+	}
+}
+
+func TestTreePQ(t *testing.T) {
+	pq := NewRBTree[int]().AsPriorityQueue()
+	for _, v := range []int{4, 1, 3, 1, 5} {
+		pq.Push(v)
+	}
+
+	if v, _ := pq.Peek(); v != 1 {
+		t.Fatalf("Peek: got %d", v)
+	}
+	var popped []int
+	for pq.Len() > 0 {
+		v, _ := pq.Pop()
+		popped = append(popped, v)
+	}
+	if want := []int{1, 1, 3, 4, 5}; !slices.Equal(popped, want) {
+		t.Fatalf("got %v, want %v", popped, want)
+	}
+	if _, err := pq.Pop(); !errors.Is(err, ErrEmptyTree) {
+		t.Fatalf("Pop on an empty queue: got error %v", err)
+	}
+}
+
+func TestDEPQ(t *testing.T) {
+	q := NewDEPQ[int]()
+	for _, v := range []int{4, 1, 3, 1, 5} {
+		q.Push(v)
+	}
+
+	lo, _ := q.PopMin()
+	hi, _ := q.PopMax()
+	nextLo, _ := q.PeekMin()
+	nextHi, _ := q.PeekMax()
+	if lo != 1 || hi != 5 || nextLo != 1 || nextHi != 4 || q.Len() != 3 {
+		t.Fatalf("got %d, %d, then %d, %d", lo, hi, nextLo, nextHi)
+	}
+}
+
+func TestSyncRBTree(t *testing.T) {
+	st := NewSyncRBTree[int]()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				st.Insert(g*100 + i)
+				st.Exists(i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if st.Len() != 800 || !slices.IsSorted(st.GetValues()) {
+		t.Fatalf("got Len %d", st.Len())
+	}
+	if got := slices.Collect(st.SnapshotIter()); len(got) != 800 {
+		t.Fatalf("snapshot has %d values", len(got))
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !goalds_debug
+
+package bst
+
+// No-op unless built with the goalds_debug tag. See debug_on.go.
+func (rb *RBTree[T]) debugValidate() {}
@@ -0,0 +1,17 @@
+//go:build goalds_debug
+
+package bst
+
+import "fmt"
+
+// Debug mode is enabled by building with the goalds_debug tag, e.g.
+//
+//	go test -tags goalds_debug ./...
+//
+// In this mode every mutation validates the whole tree afterwards and panics on any violation.
+// This makes mutations O(n), so it is meant only for development and tests.
+func (rb *RBTree[T]) debugValidate() {
+	if err := rb.Validate(); err != nil {
+		panic(fmt.Sprintf("goalds: invalid tree after mutation: %v", err))
+	}
+}
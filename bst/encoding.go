@@ -0,0 +1,241 @@
+package bst
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// A node in the shape encoding. Nil nodes are encoded as null.
+type shapeNode[T any] struct {
+	Value T    `json:"value"`
+	Red   bool `json:"red,omitempty"`
+}
+
+// Encodes the exact shape of the tree as a JSON array of nodes in preorder, with null for nil children.
+// Each node holds its value and color, so UnmarshalShape reconstructs a structurally identical tree.
+// Values are encoded using encoding/json.
+func (rb *RBTree[T]) MarshalShape() ([]byte, error) {
+	nodes := make([]*shapeNode[T], 0, 2*rb.len+1)
+	stack := []*node[T]{rb.root}
+
+	for len(stack) > 0 {
+		nd := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if nd == nil {
+			nodes = append(nodes, nil)
+			continue
+		}
+
+		nodes = append(nodes, &shapeNode[T]{
+			Value: nd.value,
+			Red:   nd.clr == red,
+		})
+		// push right first so that left subtree is encoded first
+		stack = append(stack, nd.right, nd.left)
+	}
+
+	return json.Marshal(nodes)
+}
+
+// Replaces the contents of the tree with the one encoded by MarshalShape, preserving its exact shape.
+// The decoded tree is validated against the tree's ordering and the red-black properties.
+// If the data is malformed or the decoded tree is invalid, an error is returned and the tree is left unchanged.
+func (rb *RBTree[T]) UnmarshalShape(data []byte) error {
+	var nodes []*shapeNode[T]
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		return fmt.Errorf("unmarshal shape: %w", err)
+	}
+
+	root, ok := decodeShape(nodes)
+	if !ok {
+		return fmt.Errorf("unmarshal shape: malformed preorder encoding")
+	}
+
+	decoded := &RBTree[T]{
+		cmp:       rb.cmp,
+		balancing: rb.balancing,
+	}
+	decoded.setRoot(root, root.size())
+	if err := decoded.Validate(); err != nil {
+		return fmt.Errorf("unmarshal shape: %w", err)
+	}
+
+	rb.replaceContents(decoded.root, decoded.len)
+	return nil
+}
+
+// Replaces all nodes of the tree with the tree rooted at root having n nodes,
+// reporting the old values as deleted and the new ones as inserted.
+func (rb *RBTree[T]) replaceContents(root *node[T], n int) {
+	old := &RBTree[T]{}
+	old.setRoot(rb.root, rb.len)
+	rb.setRoot(root, n)
+
+	if rb.onChange != nil {
+		for nd := old.minNode(); nd != nil; nd = nd.successor() {
+			rb.notify(Deleted, nd.value)
+		}
+		for nd := rb.minNode(); nd != nil; nd = nd.successor() {
+			rb.notify(Inserted, nd.value)
+		}
+	}
+}
+
+// Encodes the values of the tree in ascending order in a compact binary format:
+// the number of values as a little-endian uint64, followed by the little-endian encoding of each value.
+// The element type must have a fixed, non-zero size, as defined by encoding/binary, like the numeric types,
+// or arrays and structs of them. Otherwise, an error is returned. Note that int and uint don't have a fixed size,
+// so use a sized type like int64 instead.
+// Unlike MarshalShape, only the values are encoded, not the shape of the tree.
+func (rb *RBTree[T]) MarshalBinary() ([]byte, error) {
+	var zero T
+	size := binary.Size(zero)
+	if size <= 0 {
+		return nil, fmt.Errorf("marshal binary: element type %T does not have a fixed, non-zero size", zero)
+	}
+
+	data := make([]byte, 8, 8+size*rb.len)
+	binary.LittleEndian.PutUint64(data, uint64(rb.len))
+	return binary.Append(data, binary.LittleEndian, rb.GetValues())
+}
+
+// Replaces the contents of the tree with the values encoded by MarshalBinary.
+// The values must be in ascending order according to the tree's ordering, and are built into
+// a balanced tree in O(n) time. The duplicate policy is not applied to the decoded values.
+// If the data is malformed or the values are not sorted, an error is returned and the tree is left unchanged.
+func (rb *RBTree[T]) UnmarshalBinary(data []byte) error {
+	var zero T
+	size := binary.Size(zero)
+	if size <= 0 {
+		return fmt.Errorf("unmarshal binary: element type %T does not have a fixed, non-zero size", zero)
+	}
+
+	if len(data) < 8 {
+		return fmt.Errorf("unmarshal binary: missing length prefix")
+	}
+	n := binary.LittleEndian.Uint64(data)
+	data = data[8:]
+
+	// compare by division, since n*size might overflow for corrupted data
+	if len(data)%size != 0 || uint64(len(data)/size) != n {
+		return fmt.Errorf("unmarshal binary: %d values don't match %d bytes of data", n, len(data))
+	}
+
+	values := make([]T, n)
+	if _, err := binary.Decode(data, binary.LittleEndian, values); err != nil {
+		return fmt.Errorf("unmarshal binary: %w", err)
+	}
+	if !slices.IsSortedFunc(values, rb.cmp) {
+		return fmt.Errorf("unmarshal binary: values are not sorted")
+	}
+
+	rb.replaceContents(buildBalanced(values), len(values))
+	rb.debugValidate()
+	return nil
+}
+
+// Decodes a preorder encoding into a tree and returns its root.
+// Returns false if the encoding ends early or has trailing nodes.
+// Uses an explicit stack, since the encoding may describe an arbitrarily tall tree.
+func decodeShape[T any](nodes []*shapeNode[T]) (*node[T], bool) {
+	var root *node[T] = nil
+
+	// a slot is a child pointer of parent waiting to be filled, in preorder
+	type slot struct {
+		parent *node[T]
+		child  **node[T]
+	}
+
+	stack := []slot{{child: &root}}
+	// decoded nodes in preorder
+	decoded := make([]*node[T], 0, len(nodes)/2)
+	i := 0
+
+	for len(stack) > 0 {
+		if i >= len(nodes) {
+			return nil, false
+		}
+
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		sn := nodes[i]
+		i++
+		if sn == nil {
+			continue
+		}
+
+		nd := &node[T]{
+			value:  sn.Value,
+			parent: s.parent,
+		}
+		if sn.Red {
+			nd.clr = red
+		}
+		*s.child = nd
+		decoded = append(decoded, nd)
+
+		// push right first so that left subtree is decoded first
+		stack = append(stack, slot{nd, &nd.right}, slot{nd, &nd.left})
+	}
+
+	if i != len(nodes) {
+		return nil, false
+	}
+
+	// in reverse preorder, every node comes after all its descendants
+	for j := len(decoded) - 1; j >= 0; j-- {
+		decoded[j].update()
+	}
+
+	return root, true
+}
+
+// Returns the shape of the tree as nested slices, meant for asserting exact shapes in tests with reflect.DeepEqual.
+// Each node is a []any of its value, its color as "R" or "B", and its left and right subtrees, with nil for nil subtrees.
+// For example, a root 2 with red children 1 and 3 is
+//
+//	[]any{2, "B", []any{1, "R", nil, nil}, []any{3, "R", nil, nil}}
+//
+// Returns nil for an empty tree. The tree is not modified.
+func (rb *RBTree[T]) Structure() any {
+	if rb.root == nil {
+		return nil
+	}
+
+	newSlice := func(nd *node[T]) []any {
+		clr := "B"
+		if nd.clr == red {
+			clr = "R"
+		}
+		return []any{nd.value, clr, nil, nil}
+	}
+
+	// a subtree waiting to be filled in at index i of slice s
+	type slot struct {
+		nd *node[T]
+		s  []any
+		i  int
+	}
+
+	root := newSlice(rb.root)
+	stack := []slot{{rb.root.left, root, 2}, {rb.root.right, root, 3}}
+
+	for len(stack) > 0 {
+		sl := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if sl.nd == nil {
+			// left as an untyped nil, so that it compares equal to nil in expected values
+			continue
+		}
+
+		s := newSlice(sl.nd)
+		sl.s[sl.i] = s
+		stack = append(stack, slot{sl.nd.left, s, 2}, slot{sl.nd.right, s, 3})
+	}
+
+	return root
+}
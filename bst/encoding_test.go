@@ -0,0 +1,79 @@
+package bst
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShapeRoundTrip(t *testing.T) {
+	rb := NewRBTree[int]()
+	rb.InsertAll(5, 1, 3, 3, 9, 7, 11, 12)
+
+	data, err := rb.MarshalShape()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := NewRBTree[int]()
+	decoded.Insert(100)
+	if err := decoded.UnmarshalShape(data); err != nil {
+		t.Fatal(err)
+	}
+	checkValues(t, decoded, rb.GetValues())
+	if !reflect.DeepEqual(decoded.Structure(), rb.Structure()) {
+		t.Fatal("decoded tree has a different shape")
+	}
+}
+
+func TestUnmarshalShapeRejectsInvalid(t *testing.T) {
+	tests := map[string]string{
+		"not json":       `[`,
+		"trailing nodes": `[{"value":1},null,null,null]`,
+		"truncated":      `[{"value":1},null]`,
+		"red root":       `[{"value":1,"red":true},null,null]`,
+		"out of order":   `[{"value":1},{"value":2,"red":true},null,null,null]`,
+	}
+
+	for name, data := range tests {
+		rb := NewRBTree[int]()
+		rb.InsertAll(4, 5)
+		if err := rb.UnmarshalShape([]byte(data)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+		checkValues(t, rb, []int{4, 5})
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int64{-3, 1, 1, 8, 1 << 40})
+
+	data, err := rb.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := NewRBTree[int64]()
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	checkValues(t, decoded, rb.GetValues())
+
+	if err := decoded.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Error("truncated data: no error")
+	}
+	if _, err := NewRBTree[int]().MarshalBinary(); err == nil {
+		t.Error("int has no fixed size, but was marshaled")
+	}
+}
+
+func TestStructure(t *testing.T) {
+	if got := NewRBTree[int]().Structure(); got != nil {
+		t.Fatalf("empty tree: got %v", got)
+	}
+
+	rb := NewRBTreeFromSlice([]int{1, 2, 3})
+	want := []any{2, "B", []any{1, "R", nil, nil}, []any{3, "R", nil, nil}}
+	if got := rb.Structure(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -2,4 +2,13 @@ package bst
 
 import "fmt"
 
-var ErrValueDoesNotExist = fmt.Errorf("value does not exist")
+// Errors returned by tree operations. They may be wrapped with additional context,
+// so use errors.Is to check for them.
+var (
+	ErrValueDoesNotExist = fmt.Errorf("value does not exist")
+	ErrKeyDoesNotExist   = fmt.Errorf("key does not exist")
+	ErrIndexOutOfRange   = fmt.Errorf("index out of range")
+	ErrEmptyTree         = fmt.Errorf("tree is empty")
+	ErrInvalidRange      = fmt.Errorf("invalid range")
+	ErrOutOfOrder        = fmt.Errorf("value out of order")
+)
@@ -0,0 +1,32 @@
+package bst
+
+// ChangeKind tells whether a ChangeEvent is for an inserted or a deleted value.
+type ChangeKind int
+
+const (
+	// The value was added to the tree.
+	Inserted ChangeKind = iota
+	// The value was removed from the tree. A value overwritten under ReplaceDuplicates
+	// is reported as Deleted, followed by the new value as Inserted.
+	Deleted
+)
+
+// ChangeEvent describes a single value inserted into or deleted from a tree.
+type ChangeEvent[T any] struct {
+	Kind  ChangeKind
+	Value T
+}
+
+// Registers fn to be called after each value is inserted or deleted, replacing any previous observer.
+// Bulk operations report one event per value. Passing nil removes the observer.
+// The callback runs synchronously within the mutation, and must not modify or query the tree.
+// When no observer is registered, mutations only pay for a nil check.
+func (rb *RBTree[T]) OnChange(fn func(event ChangeEvent[T])) {
+	rb.onChange = fn
+}
+
+func (rb *RBTree[T]) notify(kind ChangeKind, val T) {
+	if rb.onChange != nil {
+		rb.onChange(ChangeEvent[T]{Kind: kind, Value: val})
+	}
+}
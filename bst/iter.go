@@ -0,0 +1,119 @@
+package bst
+
+import "iter"
+
+// Panics if the tree has been structurally modified since gen was read from it.
+// Iterators call this after every yield, before stepping to the next node, since the node they are at
+// might have been removed from the tree. Like the fail-fast iterators of Java, this is a best-effort
+// check to turn misuse into a clear failure. It is not a substitute for locking: modifications
+// from other goroutines are data races, which might go undetected.
+func (rb *RBTree[T]) checkGen(gen uint64) {
+	if rb.gen != gen {
+		panic("goalds: tree modified during iteration")
+	}
+}
+
+// Returns an iterator over values greater than or equal to start, in ascending order.
+// Iteration begins at the ceiling of start and steps through successors using parent pointers.
+func (rb *RBTree[T]) From(start T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		gen := rb.gen
+		for nd := rb.ceilingNode(start); nd != nil; nd = nd.successor() {
+			if !yield(nd.value) {
+				return
+			}
+			rb.checkGen(gen)
+		}
+	}
+}
+
+// Returns an iterator over values less than or equal to start, in descending order.
+// Iteration begins at the floor of start and steps through predecessors using parent pointers.
+func (rb *RBTree[T]) DownFrom(start T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		gen := rb.gen
+		for nd := rb.floorNode(start); nd != nil; nd = nd.predecessor() {
+			if !yield(nd.value) {
+				return
+			}
+			rb.checkGen(gen)
+		}
+	}
+}
+
+// Returns an iterator over all values in ascending order.
+func (rb *RBTree[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		gen := rb.gen
+		for nd := rb.minNode(); nd != nil; nd = nd.successor() {
+			if !yield(nd.value) {
+				return
+			}
+			rb.checkGen(gen)
+		}
+	}
+}
+
+// Returns an iterator over all values in descending order.
+func (rb *RBTree[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		gen := rb.gen
+		for nd := rb.maxNode(); nd != nil; nd = nd.predecessor() {
+			if !yield(nd.value) {
+				return
+			}
+			rb.checkGen(gen)
+		}
+	}
+}
+
+// Returns an iterator over all values in ascending order, along with the insertion sequence number of each.
+// Sequence numbers are only stamped with WithInsertionOrder, by Insert and its variants, and Concat keeps them.
+// Values that got their node any other way, like through UnmarshalBinary, or when the option is not used,
+// have sequence number 0.
+func (rb *RBTree[T]) AllWithSeq() iter.Seq2[T, uint64] {
+	return func(yield func(T, uint64) bool) {
+		gen := rb.gen
+		for nd := rb.minNode(); nd != nil; nd = nd.successor() {
+			if !yield(nd.value, nd.seq) {
+				return
+			}
+			rb.checkGen(gen)
+		}
+	}
+}
+
+// Returns an iterator over the distinct values in ascending order, yielding each value once,
+// no matter how many nodes hold it. Equal values are adjacent in inorder traversal, so duplicates are skipped
+// without any extra memory.
+func (rb *RBTree[T]) Distinct() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		gen := rb.gen
+		for nd := rb.minNode(); nd != nil; {
+			if !yield(nd.value) {
+				return
+			}
+			rb.checkGen(gen)
+
+			v := nd.value
+			for nd != nil && rb.cmp(nd.value, v) == 0 {
+				nd = nd.successor()
+			}
+		}
+	}
+}
+
+// Returns an iterator over all values in ascending order, along with the 0-based position of each.
+func (rb *RBTree[T]) Enumerate() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		gen := rb.gen
+		i := 0
+		for nd := rb.minNode(); nd != nil; nd = nd.successor() {
+			if !yield(i, nd.value) {
+				return
+			}
+			rb.checkGen(gen)
+			i++
+		}
+	}
+}
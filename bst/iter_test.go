@@ -0,0 +1,273 @@
+package bst
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFromDownFrom(t *testing.T) {
+	rb := NewRBTree[int]()
+	for _, v := range []int{5, 1, 3, 3, 9, 7} {
+		rb.Insert(v)
+	}
+
+	tests := []struct {
+		name string
+		got  []int
+		want []int
+	}{
+		{"From", slices.Collect(rb.From(3)), []int{3, 3, 5, 7, 9}},
+		{"From past the end", slices.Collect(rb.From(10)), nil},
+		{"DownFrom", slices.Collect(rb.DownFrom(6)), []int{5, 3, 3, 1}},
+		{"DownFrom before the start", slices.Collect(rb.DownFrom(0)), nil},
+	}
+	for _, tt := range tests {
+		if !slices.Equal(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestAllBackward(t *testing.T) {
+	rb := NewRBTree[int]()
+	rb.InsertAll(5, 1, 3, 3, 9, 7)
+
+	if got, want := slices.Collect(rb.All()), []int{1, 3, 3, 5, 7, 9}; !slices.Equal(got, want) {
+		t.Errorf("All: got %v, want %v", got, want)
+	}
+	if got, want := slices.Collect(rb.Backward()), []int{9, 7, 5, 3, 3, 1}; !slices.Equal(got, want) {
+		t.Errorf("Backward: got %v, want %v", got, want)
+	}
+	if got := slices.Collect(NewRBTree[int]().Backward()); len(got) != 0 {
+		t.Errorf("Backward on an empty tree: got %v", got)
+	}
+}
+
+func TestDistinct(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{5, 1, 3, 3, 9, 7, 7})
+
+	if got, want := slices.Collect(rb.Distinct()), []int{1, 3, 5, 7, 9}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestEnumerate(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{5, 1, 3, 3, 9, 7})
+	vals := rb.GetValues()
+
+	i := 0
+	for k, v := range rb.Enumerate() {
+		if k != i || v != vals[k] {
+			t.Fatalf("got %d, %d at position %d", k, v, i)
+		}
+		i++
+	}
+	if i != len(vals) {
+		t.Fatalf("got %d values, want %d", i, len(vals))
+	}
+}
+
+func TestIteratorStopsEarly(t *testing.T) {
+	rb := NewRBTree[int]()
+	rb.InsertAll(1, 2, 3, 4, 5)
+
+	var got []int
+	for v := range rb.All() {
+		if v > 3 {
+			break
+		}
+		got = append(got, v)
+	}
+	if want := []int{1, 2, 3}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestIteratorPanicsOnModification(t *testing.T) {
+	iterators := map[string]func(rb *RBTree[int]) func(func(int) bool){
+		"All":        func(rb *RBTree[int]) func(func(int) bool) { return rb.All() },
+		"Backward":   func(rb *RBTree[int]) func(func(int) bool) { return rb.Backward() },
+		"Preorder":   func(rb *RBTree[int]) func(func(int) bool) { return rb.Preorder() },
+		"Postorder":  func(rb *RBTree[int]) func(func(int) bool) { return rb.Postorder() },
+		"LevelOrder": func(rb *RBTree[int]) func(func(int) bool) { return rb.LevelOrder() },
+	}
+
+	for name, seq := range iterators {
+		t.Run(name, func(t *testing.T) {
+			rb := NewRBTreeFromSlice([]int{1, 2, 3, 4, 5})
+
+			defer func() {
+				if recover() == nil {
+					t.Fatal("modifying the tree during iteration did not panic")
+				}
+			}()
+			for v := range seq(rb) {
+				_ = rb.Delete(v)
+			}
+		})
+	}
+}
+
+func TestWalkPrune(t *testing.T) {
+	// inserting in level order builds this shape without any rotations
+	//       4
+	//     /   \
+	//    2     6
+	//   / \   / \
+	//  1   3 5   7
+	rb := NewRBTree[int]()
+	rb.InsertAll(4, 2, 6, 1, 3, 5, 7)
+
+	var pruned []int
+	rb.WalkPrune(func(v int) (bool, bool) {
+		pruned = append(pruned, v)
+		return v > 3, v < 5
+	})
+	if want := []int{4, 2, 3, 6, 5}; !slices.Equal(pruned, want) {
+		t.Errorf("got %v, want %v", pruned, want)
+	}
+}
+
+func TestTraversals(t *testing.T) {
+	//       4
+	//     /   \
+	//    2     6
+	//   / \   / \
+	//  1   3 5   7
+	rb := NewRBTreeFromSlice([]int{1, 2, 3, 4, 5, 6, 7})
+
+	tests := []struct {
+		name string
+		got  []int
+		want []int
+	}{
+		{"Preorder", slices.Collect(rb.Preorder()), []int{4, 2, 1, 3, 6, 5, 7}},
+		{"Postorder", slices.Collect(rb.Postorder()), []int{1, 3, 2, 5, 7, 6, 4}},
+		{"LevelOrder", slices.Collect(rb.LevelOrder()), []int{4, 2, 6, 1, 3, 5, 7}},
+	}
+	for _, tt := range tests {
+		if !slices.Equal(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLevelOrderColored(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{1, 2, 3, 4, 5, 6, 7})
+
+	// the black root 4 over the black 2 and 6, and the red leaves 1, 3, 5 and 7
+	levels := rb.LevelOrderColored()
+	if len(levels) != 3 || len(levels[2]) != 4 || levels[0][0].Value != 4 || levels[0][0].Red || !levels[2][0].Red {
+		t.Errorf("got %v", levels)
+	}
+}
+
+// Returns a tree of the values 0, ..., n-1 in a single chain of right children, like InsertNoFixup
+// leaves after ascending insertions. The chain is built directly, since inserting would take O(n^2) time.
+func tallTree(n int) *RBTree[int] {
+	var root *node[int]
+	for i := n - 1; i >= 0; i-- {
+		nd := &node[int]{value: i, right: root}
+		if root != nil {
+			root.parent = nd
+		}
+		nd.update()
+		root = nd
+	}
+
+	rb := NewRBTree[int]()
+	rb.setRoot(root, n)
+	return rb
+}
+
+// Records the deepest depth passed to Enter.
+type depthVisitor struct {
+	maxDepth int
+}
+
+func (v *depthVisitor) Enter(value int, depth int) {
+	v.maxDepth = max(v.maxDepth, depth)
+}
+
+func (v *depthVisitor) Exit(value int, depth int) {}
+
+func TestTallTreeTraversals(t *testing.T) {
+	const n = 1 << 17
+	rb := tallTree(n)
+
+	asc := make([]int, n)
+	for i := range asc {
+		asc[i] = i
+	}
+	desc := slices.Clone(asc)
+	slices.Reverse(desc)
+
+	tests := []struct {
+		name string
+		got  []int
+		want []int
+	}{
+		{"All", slices.Collect(rb.All()), asc},
+		{"Backward", slices.Collect(rb.Backward()), desc},
+		{"Preorder", slices.Collect(rb.Preorder()), asc},
+		{"Postorder", slices.Collect(rb.Postorder()), desc},
+		{"LevelOrder", slices.Collect(rb.LevelOrder()), asc},
+	}
+	for _, tt := range tests {
+		if !slices.Equal(tt.got, tt.want) {
+			t.Errorf("%s: got %d values, want %d in order", tt.name, len(tt.got), len(tt.want))
+		}
+	}
+
+	visited := 0
+	rb.WalkPrune(func(int) (bool, bool) {
+		visited++
+		return true, true
+	})
+	if visited != n {
+		t.Errorf("WalkPrune: visited %d values, want %d", visited, n)
+	}
+
+	var v depthVisitor
+	rb.Accept(&v)
+	if v.maxDepth != n-1 {
+		t.Errorf("Accept: got max depth %d, want %d", v.maxDepth, n-1)
+	}
+}
+
+// Records the calls of Accept as a nested string, like (4 (2 (1) (3)) (6)).
+type nestingVisitor struct {
+	out      []byte
+	maxDepth int
+}
+
+func (v *nestingVisitor) Enter(value int, depth int) {
+	if len(v.out) > 0 && v.out[len(v.out)-1] != '(' {
+		v.out = append(v.out, ' ')
+	}
+	v.out = append(v.out, '(', byte('0'+value))
+	v.maxDepth = max(v.maxDepth, depth)
+}
+
+func (v *nestingVisitor) Exit(value int, depth int) {
+	v.out = append(v.out, ')')
+}
+
+func TestAccept(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{1, 2, 3, 4, 5})
+
+	v := &nestingVisitor{}
+	rb.Accept(v)
+	if want := "(3 (2 (1)) (5 (4)))"; string(v.out) != want {
+		t.Fatalf("got %s, want %s", v.out, want)
+	}
+	if v.maxDepth != rb.Height()-1 {
+		t.Fatalf("deepest depth %d in a tree of height %d", v.maxDepth, rb.Height())
+	}
+
+	empty := &nestingVisitor{}
+	NewRBTree[int]().Accept(empty)
+	if len(empty.out) != 0 {
+		t.Fatalf("empty tree: got %s", empty.out)
+	}
+}
@@ -0,0 +1,161 @@
+package bst
+
+import (
+	"cmp"
+	"iter"
+)
+
+type multisetEntry[T any] struct {
+	value T
+	count int
+}
+
+// RBMultiset is a sorted multiset that stores each distinct value once along with its multiplicity.
+// For workloads with many duplicates, this is far more compact than an RBTree, which keeps a node per copy.
+type RBMultiset[T cmp.Ordered] struct {
+	tree *RBTree[multisetEntry[T]]
+	// sum of the counts of all values
+	total int
+}
+
+func NewRBMultiset[T cmp.Ordered]() *RBMultiset[T] {
+	return &RBMultiset[T]{
+		tree: NewRBTreeFunc(compareMultisetEntries[T]),
+	}
+}
+
+func compareMultisetEntries[T cmp.Ordered](a, b multisetEntry[T]) int {
+	return cmp.Compare(a.value, b.value)
+}
+
+// Returns the total multiplicity, i.e. the number of values counting every copy.
+func (ms *RBMultiset[T]) Len() int {
+	return ms.total
+}
+
+// Returns the number of distinct values.
+func (ms *RBMultiset[T]) DistinctLen() int {
+	return ms.tree.Len()
+}
+
+// Adds n copies of v. Does nothing if n is not positive.
+func (ms *RBMultiset[T]) Add(v T, n int) {
+	if n <= 0 {
+		return
+	}
+
+	nd, inserted := ms.tree.insertUnique(multisetEntry[T]{value: v, count: n})
+	if !inserted {
+		nd.value.count += n
+	}
+	ms.total += n
+}
+
+// Removes up to n copies of v, and returns the number of copies actually removed.
+// The value is removed entirely once its count drops to zero. Does nothing if n is not positive.
+func (ms *RBMultiset[T]) Remove(v T, n int) int {
+	if n <= 0 {
+		return 0
+	}
+
+	nd := ms.tree.findNode(multisetEntry[T]{value: v})
+	if nd == nil {
+		return 0
+	}
+
+	if n >= nd.value.count {
+		n = nd.value.count
+		ms.tree.deleteNode(nd)
+	} else {
+		nd.value.count -= n
+	}
+
+	ms.total -= n
+	return n
+}
+
+// Sets the number of copies of v to exactly n, adding v if it is absent, or removing it entirely if n is zero.
+// A negative n is treated as zero.
+func (ms *RBMultiset[T]) SetCount(v T, n int) {
+	n = max(n, 0)
+
+	if n == 0 {
+		ms.Remove(v, ms.Count(v))
+		return
+	}
+
+	nd, inserted := ms.tree.insertUnique(multisetEntry[T]{value: v, count: n})
+	if !inserted {
+		ms.total -= nd.value.count
+		nd.value.count = n
+	}
+	ms.total += n
+}
+
+// Adds all copies of every value in other, summing the counts of values in both. other is left unchanged.
+// Walks both multisets once in ascending order, updating the counts of shared values in place,
+// and then inserts the values that only other has. Takes O(n + m + k log n) time for k such values.
+func (ms *RBMultiset[T]) AddAll(other *RBMultiset[T]) {
+	added := other.total
+	var missing []multisetEntry[T]
+	a, b := ms.tree.minNode(), other.tree.minNode()
+
+	for b != nil {
+		// once a runs out, the remaining values of other are all missing
+		c := 1
+		if a != nil {
+			c = cmp.Compare(a.value.value, b.value.value)
+		}
+
+		if c < 0 {
+			a = a.successor()
+			continue
+		}
+
+		if c == 0 {
+			a.value.count += b.value.count
+			a = a.successor()
+		} else {
+			missing = append(missing, b.value)
+		}
+		b = b.successor()
+	}
+
+	for _, e := range missing {
+		ms.tree.insertUnique(e)
+	}
+	ms.total += added
+}
+
+// Returns the number of copies of v.
+func (ms *RBMultiset[T]) Count(v T) int {
+	nd := ms.tree.findNode(multisetEntry[T]{value: v})
+	if nd == nil {
+		return 0
+	}
+	return nd.value.count
+}
+
+// Returns an iterator over the distinct values and their counts, in ascending order of values.
+func (ms *RBMultiset[T]) All() iter.Seq2[T, int] {
+	return func(yield func(T, int) bool) {
+		for e := range ms.tree.All() {
+			if !yield(e.value, e.count) {
+				return
+			}
+		}
+	}
+}
+
+// Returns an iterator over the values in ascending order, yielding each value as many times as its count.
+func (ms *RBMultiset[T]) Expanded() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := range ms.tree.All() {
+			for range e.count {
+				if !yield(e.value) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package bst
+
+import "slices"
+
+// Integer is satisfied by all integer types.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Number is satisfied by all integer and floating-point types.
+type Number interface {
+	Integer | ~float32 | ~float64
+}
+
+// Returns the start and length of the longest run of stored values where each is exactly one more than the previous.
+// Duplicates neither break nor extend a run. If several runs are equally long, the first one is returned.
+// Returns 0, 0 for an empty tree. Takes O(n) time.
+// This is a function rather than a method, since methods can't further constrain the type of the tree.
+func LongestConsecutiveRun[T Integer](rb *RBTree[T]) (start T, length int) {
+	var runStart T
+	runLen := 0
+	var prev *node[T] = nil
+
+	for nd := rb.minNode(); nd != nil; nd = nd.successor() {
+		if prev != nil && nd.value == prev.value {
+			continue
+		}
+
+		if prev != nil && nd.value == prev.value+1 {
+			runLen++
+		} else {
+			runStart, runLen = nd.value, 1
+		}
+
+		if runLen > length {
+			start, length = runStart, runLen
+		}
+		prev = nd
+	}
+
+	return start, length
+}
+
+// Returns the inclusive ranges of missing integers between consecutive stored values, in ascending order.
+// For example, a tree with 1, 2, 5, 6 and 9 has the gaps {3, 4} and {7, 8}.
+// Duplicates are skipped. An empty or single-valued tree has no gaps. Takes O(n) time.
+func Gaps[T Integer](rb *RBTree[T]) [][2]T {
+	var gaps [][2]T
+	var prev *node[T] = nil
+
+	for nd := rb.minNode(); nd != nil; nd = nd.successor() {
+		// prev.value+1 can only overflow when prev.value is the maximum, which the first check rules out
+		if prev != nil && nd.value > prev.value && prev.value+1 < nd.value {
+			gaps = append(gaps, [2]T{prev.value + 1, nd.value - 1})
+		}
+		prev = nd
+	}
+
+	return gaps
+}
+
+// Returns the k stored values closest to pivot by absolute difference, in the tree's order.
+// On a tie in distance, the value before pivot in the tree's order wins. Returns all values if there are fewer than k.
+// Starting from the floor and the successor of pivot, this expands outwards, picking the closer side each step.
+// Takes O(log n + k) time. The distances must be representable in T, which holds unless the values span
+// more than the range of a signed integer type.
+func ClosestK[T Number](rb *RBTree[T], pivot T, k int) []T {
+	if k <= 0 {
+		return nil
+	}
+
+	dist := func(v T) T {
+		if v > pivot {
+			return v - pivot
+		}
+		return pivot - v
+	}
+
+	// values before pivot are collected in reverse
+	var before, after []T
+	lo, hi := rb.floorNode(pivot), rb.higherNode(pivot)
+
+	for len(before)+len(after) < k && (lo != nil || hi != nil) {
+		if hi == nil || (lo != nil && dist(lo.value) <= dist(hi.value)) {
+			before = append(before, lo.value)
+			lo = lo.predecessor()
+		} else {
+			after = append(after, hi.value)
+			hi = hi.successor()
+		}
+	}
+
+	slices.Reverse(before)
+	return append(before, after...)
+}
+
+// Returns the difference between the largest and the smallest value, i.e. the width of the range of values.
+// Returns false for an empty tree. A tree with a single value, or with only equal values, has a span of 0.
+// Takes O(log n) time.
+func Span[T Number](rb *RBTree[T]) (T, bool) {
+	if rb.root == nil {
+		var zero T
+		return zero, false
+	}
+
+	// with a custom comparator, the extremes of the tree's order might be reversed
+	first, last := rb.minNode().value, rb.maxNode().value
+	return max(first, last) - min(first, last), true
+}
+
+// Returns the integers in [lo, hi] that are not stored in the tree, in ascending order.
+// For example, with 2 and 4 stored, the complement in [1, 5] is 1, 3 and 5, which makes this useful
+// for finding free IDs in a known pool. Duplicates count as a single stored value.
+// Returns nil if lo > hi. The stored values in the range are walked once, and the gaps between them emitted,
+// so this takes O(log n + k + hi - lo) time, where k is the number of stored values in the range.
+// The tree must be in ascending order of the integers.
+func ComplementInRange[T Integer](rb *RBTree[T], lo, hi T) []T {
+	if lo > hi {
+		return nil
+	}
+
+	var res []T
+	// the smallest integer not yet emitted or found in the tree
+	next := lo
+
+	for nd := rb.ceilingNode(lo); nd != nil && nd.value <= hi; nd = nd.successor() {
+		if nd.value < next {
+			// a duplicate
+			continue
+		}
+		for v := next; v < nd.value; v++ {
+			res = append(res, v)
+		}
+		if nd.value == hi {
+			return res
+		}
+		// can't overflow, since nd.value < hi
+		next = nd.value + 1
+	}
+
+	// loop on v != hi rather than v <= hi, which would never end if hi is the maximum of T
+	for v := next; ; v++ {
+		res = append(res, v)
+		if v == hi {
+			break
+		}
+	}
+	return res
+}
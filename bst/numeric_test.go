@@ -0,0 +1,81 @@
+package bst
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestLongestConsecutiveRun(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{1, 2, 2, 3, 7, 8, 9, 10, 20})
+	if start, n := LongestConsecutiveRun(rb); start != 7 || n != 4 {
+		t.Fatalf("got %d, %d", start, n)
+	}
+	if start, n := LongestConsecutiveRun(NewRBTree[int]()); start != 0 || n != 0 {
+		t.Fatalf("empty tree: got %d, %d", start, n)
+	}
+}
+
+func TestGaps(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int8{1, 2, 5, 5, 6, 9, math.MaxInt8})
+	want := [][2]int8{{3, 4}, {7, 8}, {10, math.MaxInt8 - 1}}
+	if got := Gaps(rb); !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestComplementInRange(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int8{math.MinInt8, 2, 4, 4, math.MaxInt8})
+
+	tests := []struct {
+		lo, hi int8
+		want   []int8
+	}{
+		{1, 5, []int8{1, 3, 5}},
+		{2, 4, []int8{3}},
+		{4, 4, nil},
+		{5, 1, nil},
+		{120, math.MaxInt8, []int8{120, 121, 122, 123, 124, 125, 126}},
+		{124, 126, []int8{124, 125, 126}},
+		{math.MinInt8, -125, []int8{-127, -126, -125}},
+	}
+	for _, tt := range tests {
+		if got := ComplementInRange(rb, tt.lo, tt.hi); !slices.Equal(got, tt.want) {
+			t.Errorf("ComplementInRange(%d, %d): got %v, want %v", tt.lo, tt.hi, got, tt.want)
+		}
+	}
+}
+
+func TestClosestK(t *testing.T) {
+	rb := NewRBTreeFromSlice([]float64{1, 4, 6, 7, 12})
+
+	tests := []struct {
+		pivot float64
+		k     int
+		want  []float64
+	}{
+		{5, 2, []float64{4, 6}},
+		{5, 3, []float64{4, 6, 7}},
+		{0, 2, []float64{1, 4}},
+		{20, 1, []float64{12}},
+		{5, 10, []float64{1, 4, 6, 7, 12}},
+		{5, 0, nil},
+	}
+	for _, tt := range tests {
+		if got := ClosestK(rb, tt.pivot, tt.k); !slices.Equal(got, tt.want) {
+			t.Errorf("ClosestK(%v, %d): got %v, want %v", tt.pivot, tt.k, got, tt.want)
+		}
+	}
+}
+
+func TestSpan(t *testing.T) {
+	if _, ok := Span(NewRBTree[int]()); ok {
+		t.Fatal("empty tree has a span")
+	}
+
+	desc := NewRBTreeFunc(func(a, b int) int { return b - a })
+	desc.InsertAll(3, -2, 8)
+	if got, _ := Span(desc); got != 10 {
+		t.Fatalf("descending tree: got %d, want 10", got)
+	}
+}
@@ -0,0 +1,58 @@
+package bst
+
+// DuplicatePolicy decides what Insert does when the tree already has a value equal to the inserted one.
+type DuplicatePolicy int
+
+const (
+	// Insert always adds a new node, so Len grows by one on every Insert. This is the default.
+	KeepDuplicates DuplicatePolicy = iota
+	// Insert does nothing if an equal value exists, so the tree behaves as a set.
+	// Len grows only when the value is new.
+	RejectDuplicates
+	// Insert overwrites the existing equal value with the inserted one. Len grows only when the value is new.
+	// This is meaningful with a custom comparator, where equal values can carry different payloads.
+	ReplaceDuplicates
+)
+
+type treeOptions struct {
+	dups           DuplicatePolicy
+	balancing      Balancing
+	insertionOrder bool
+}
+
+// Option configures a tree at construction.
+type Option func(*treeOptions)
+
+// Sets how Insert handles values equal to an existing one. See DuplicatePolicy.
+func WithDuplicatePolicy(policy DuplicatePolicy) Option {
+	return func(o *treeOptions) {
+		o.dups = policy
+	}
+}
+
+// Sets how the tree keeps itself balanced. See Balancing.
+func WithBalancing(balancing Balancing) Option {
+	return func(o *treeOptions) {
+		o.balancing = balancing
+	}
+}
+
+// Keeps equal values in the order they were inserted, so that every traversal, like All or GetValues,
+// yields equal values in their order of arrival. This makes the tree a collection ordered by the comparator
+// and stable by arrival. Each node inserted by Insert is also stamped with an increasing sequence number,
+// which AllWithSeq reports. Every node has room for the 8-byte stamp, whether or not this option is used.
+func WithInsertionOrder() Option {
+	return func(o *treeOptions) {
+		o.insertionOrder = true
+	}
+}
+
+func (rb *RBTree[T]) applyOptions(opts []Option) {
+	var o treeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	rb.dups = o.dups
+	rb.balancing = o.balancing
+	rb.insertionOrder = o.insertionOrder
+}
@@ -0,0 +1,277 @@
+package bst
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+)
+
+// Returns the number of nodes in the subtree rooted at the first node found with the given value.
+// Returns false if the value does not exist in the tree.
+func (rb *RBTree[T]) SubtreeSize(val T) (int, bool) {
+	nd := rb.findNode(val)
+	if nd == nil {
+		return 0, false
+	}
+	return nd.sz, true
+}
+
+// Returns the 0-based position of val in the ascending order of values, or false if it does not exist.
+// If there are multiple nodes with the given value, the position of the first one is returned.
+// Takes O(log n) time using the subtree sizes.
+func (rb *RBTree[T]) IndexOf(val T) (int, bool) {
+	nd := rb.root
+	// number of nodes before the subtree of nd
+	before := 0
+	idx := -1
+
+	for nd != nil {
+		c := rb.cmp(val, nd.value)
+		if c <= 0 {
+			if c == 0 {
+				// there might be more nodes with the same value on the left
+				idx = before + nd.left.size()
+			}
+			nd = nd.left
+		} else {
+			before += nd.left.size() + 1
+			nd = nd.right
+		}
+	}
+
+	return idx, idx >= 0
+}
+
+// Returns the number of values < val, or <= val if orEqual is true. Takes O(log n) time using the subtree sizes.
+func (rb *RBTree[T]) countLess(val T, orEqual bool) int {
+	nd := rb.root
+	count := 0
+
+	for nd != nil {
+		c := rb.cmp(nd.value, val)
+		if c < 0 || (orEqual && c == 0) {
+			count += nd.left.size() + 1
+			nd = nd.right
+		} else {
+			nd = nd.left
+		}
+	}
+
+	return count
+}
+
+// Returns the largest value <= val along with its 0-based position in ascending order.
+// Returns false and a rank of -1 if there is no such value.
+// If the floor has duplicates, the position of the last one is returned.
+// Takes a single O(log n) descent, using the subtree sizes.
+func (rb *RBTree[T]) FloorRank(val T) (value T, rank int, ok bool) {
+	nd := rb.root
+	// number of nodes before the subtree of nd
+	before := 0
+	rank = -1
+
+	for nd != nil {
+		if rb.cmp(nd.value, val) <= 0 {
+			// nd is the best candidate so far, but there might be a larger one on the right
+			value, rank = nd.value, before+nd.left.size()
+			before += nd.left.size() + 1
+			nd = nd.right
+		} else {
+			nd = nd.left
+		}
+	}
+
+	return value, rank, rank >= 0
+}
+
+// Returns the number of nodes on the longest path from the root down to a leaf.
+// Returns 0 for an empty tree. Takes O(1) time since heights are maintained on every node.
+func (rb *RBTree[T]) Height() int {
+	return rb.root.height()
+}
+
+// Returns the number of nodes on the shortest and the longest path from the root down to a nil child.
+// The longest is the same as Height. In a valid red-black tree, max <= 2*min,
+// so this is a quick check of the balance guarantee. Returns 0, 0 for an empty tree.
+// Takes O(n) time, since every node is visited to find the shortest path.
+func (rb *RBTree[T]) LeafDepthRange() (min, max int) {
+	if rb.root == nil {
+		return 0, 0
+	}
+
+	type item struct {
+		nd    *node[T]
+		depth int
+	}
+	stack := []item{{rb.root, 1}}
+	min = rb.len + 1
+
+	for len(stack) > 0 {
+		it := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		// a node with a nil child ends a path at its own depth
+		if it.nd.left == nil || it.nd.right == nil {
+			if it.depth < min {
+				min = it.depth
+			}
+			if it.depth > max {
+				max = it.depth
+			}
+		}
+
+		if it.nd.left != nil {
+			stack = append(stack, item{it.nd.left, it.depth + 1})
+		}
+		if it.nd.right != nil {
+			stack = append(stack, item{it.nd.right, it.depth + 1})
+		}
+	}
+
+	return min, max
+}
+
+// Returns the node at 0-based position k in the ascending order of values, or nil if k is out of range.
+// Takes O(log n) time using the subtree sizes.
+func (rb *RBTree[T]) selectNode(k int) *node[T] {
+	if k < 0 || k >= rb.len {
+		return nil
+	}
+
+	nd := rb.root
+	for nd != nil {
+		leftSize := nd.left.size()
+		if k < leftSize {
+			nd = nd.left
+		} else if k == leftSize {
+			return nd
+		} else {
+			k -= leftSize + 1
+			nd = nd.right
+		}
+	}
+
+	return nil
+}
+
+// Returns the value at each of the given quantiles, in the same order as ps.
+// Each p is clamped to [0, 1] (NaN is treated as 0), and maps to the value at position round(p * (Len() - 1))
+// in ascending order, with halves rounded away from zero.
+// Returns nil if the tree is empty. Takes O(m log n) time for m quantiles.
+func (rb *RBTree[T]) Quantiles(ps ...float64) []T {
+	if rb.len == 0 {
+		return nil
+	}
+
+	values := make([]T, len(ps))
+	for i, p := range ps {
+		if !(p > 0) {
+			// also catches NaN
+			p = 0
+		} else if p > 1 {
+			p = 1
+		}
+
+		k := int(math.Round(p * float64(rb.len-1)))
+		values[i] = rb.selectNode(k).value
+	}
+
+	return values
+}
+
+// Returns the value at 0-based position k in ascending order, i.e. the k-th smallest value.
+// Returns an error wrapping ErrIndexOutOfRange if k is not in [0, Len()). Takes O(log n) time.
+func (rb *RBTree[T]) Select(k int) (T, error) {
+	nd := rb.selectNode(k)
+	if nd == nil {
+		var zero T
+		return zero, fmt.Errorf("select %d: %w", k, ErrIndexOutOfRange)
+	}
+	return nd.value, nil
+}
+
+// Removes and returns the value at 0-based position k in ascending order.
+// Returns an error wrapping ErrIndexOutOfRange if k is not in [0, Len()). Takes O(log n) time.
+func (rb *RBTree[T]) DeleteAt(k int) (T, error) {
+	nd := rb.selectNode(k)
+	if nd == nil {
+		var zero T
+		return zero, fmt.Errorf("delete at %d: %w", k, ErrIndexOutOfRange)
+	}
+	rb.deleteNode(nd)
+	return nd.value, nil
+}
+
+// Returns a uniformly random value from the tree using r, or false if the tree is empty.
+// Every node is equally likely, so a value with duplicates is proportionally more likely.
+// Takes O(log n) time, by selecting a random position using the subtree sizes.
+func (rb *RBTree[T]) Sample(r *rand.Rand) (T, bool) {
+	if rb.len == 0 {
+		var zero T
+		return zero, false
+	}
+	return rb.selectNode(r.Intn(rb.len)).value, true
+}
+
+// Returns k values at distinct, uniformly random positions using r, in ascending order.
+// Every subset of k positions is equally likely. Returns all values if the tree has fewer than k.
+// The positions are chosen with Floyd's algorithm, which draws exactly k random numbers without rejection,
+// and each value is then found using the subtree sizes. Takes O(k log n) time and O(k) extra memory.
+func (rb *RBTree[T]) SampleK(k int, r *rand.Rand) []T {
+	if k <= 0 {
+		return nil
+	}
+	if k >= rb.len {
+		return rb.GetValues()
+	}
+
+	chosen := make(map[int]struct{}, k)
+	for j := rb.len - k; j < rb.len; j++ {
+		i := r.Intn(j + 1)
+		if _, ok := chosen[i]; ok {
+			i = j
+		}
+		chosen[i] = struct{}{}
+	}
+
+	positions := make([]int, 0, k)
+	for i := range chosen {
+		positions = append(positions, i)
+	}
+	slices.Sort(positions)
+
+	values := make([]T, k)
+	for i, pos := range positions {
+		values[i] = rb.selectNode(pos).value
+	}
+	return values
+}
+
+// Returns the values at positions 0, n, 2n, ... in ascending order, an evenly spaced subset of ceil(Len/n) values.
+// n must be at least 1, otherwise nil is returned.
+// For small n, this is a single inorder walk in O(Len) time. For n larger than the height of the tree,
+// each value is instead found using the subtree sizes, in O((Len/n) log Len) time.
+func (rb *RBTree[T]) EveryNth(n int) []T {
+	if n < 1 || rb.len == 0 {
+		return nil
+	}
+
+	values := make([]T, 0, (rb.len+n-1)/n)
+
+	if n > rb.Height() {
+		for i := 0; i < rb.len; i += n {
+			values = append(values, rb.selectNode(i).value)
+		}
+		return values
+	}
+
+	i := 0
+	for nd := rb.minNode(); nd != nil; nd = nd.successor() {
+		if i%n == 0 {
+			values = append(values, nd.value)
+		}
+		i++
+	}
+	return values
+}
@@ -0,0 +1,181 @@
+package bst
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSubtreeSize(t *testing.T) {
+	rb, vals := randomTree(16, 200)
+
+	var count func(nd *node[int]) int
+	count = func(nd *node[int]) int {
+		if nd == nil {
+			return 0
+		}
+		return count(nd.left) + 1 + count(nd.right)
+	}
+
+	for v := -1; v < 101; v++ {
+		got, ok := rb.SubtreeSize(v)
+		nd := rb.findNode(v)
+		if ok != slices.Contains(vals, v) || (ok && got != count(nd)) {
+			t.Fatalf("SubtreeSize(%d): got %d, %t", v, got, ok)
+		}
+	}
+	// the search for the root's value stops at the root
+	if got, _ := rb.SubtreeSize(rb.root.value); got != len(vals) {
+		t.Fatalf("SubtreeSize of the root: got %d, want %d", got, len(vals))
+	}
+}
+
+func TestIndexOf(t *testing.T) {
+	rb, vals := randomTree(10, 150)
+
+	for v := -1; v < 101; v++ {
+		want := slices.Index(vals, v)
+		if got, ok := rb.IndexOf(v); ok != (want >= 0) || (ok && got != want) {
+			t.Fatalf("IndexOf(%d): got %d, %t, want %d", v, got, ok, want)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	rb, vals := randomTree(10, 150)
+
+	for k, want := range vals {
+		if got, err := rb.Select(k); err != nil || got != want {
+			t.Fatalf("Select(%d): got %d, %v, want %d", k, got, err, want)
+		}
+	}
+	for _, k := range []int{-1, len(vals)} {
+		if _, err := rb.Select(k); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Fatalf("Select(%d): got error %v", k, err)
+		}
+	}
+}
+
+func TestDeleteAt(t *testing.T) {
+	rb, vals := randomTree(11, 50)
+	r := rand.New(rand.NewSource(11))
+
+	for len(vals) > 0 {
+		k := r.Intn(len(vals))
+		got, err := rb.DeleteAt(k)
+		if err != nil || got != vals[k] {
+			t.Fatalf("DeleteAt(%d): got %d, %v, want %d", k, got, err, vals[k])
+		}
+		vals = slices.Delete(vals, k, k+1)
+		checkValues(t, rb, vals)
+	}
+}
+
+func TestFloorRank(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{2, 4, 4, 8})
+
+	tests := []struct {
+		val, want, rank int
+		ok              bool
+	}{
+		{1, 0, -1, false},
+		{2, 2, 0, true},
+		{5, 4, 2, true},
+		{9, 8, 3, true},
+	}
+	for _, tt := range tests {
+		got, rank, ok := rb.FloorRank(tt.val)
+		if ok != tt.ok || rank != tt.rank || (ok && got != tt.want) {
+			t.Errorf("FloorRank(%d): got %d, %d, %t", tt.val, got, rank, ok)
+		}
+	}
+}
+
+func TestEveryNth(t *testing.T) {
+	rb, vals := randomTree(12, 100)
+
+	for _, n := range []int{1, 3, 7, 50, 200} {
+		var want []int
+		for i := 0; i < len(vals); i += n {
+			want = append(want, vals[i])
+		}
+		if got := rb.EveryNth(n); !slices.Equal(got, want) {
+			t.Fatalf("EveryNth(%d): got %v, want %v", n, got, want)
+		}
+	}
+	if got := rb.EveryNth(0); got != nil {
+		t.Fatalf("EveryNth(0): got %v", got)
+	}
+}
+
+func TestQuantiles(t *testing.T) {
+	rb := NewRBTree[int]()
+	rb.InsertAll(10, 20, 30, 40, 50)
+
+	got := rb.Quantiles(0, 0.5, 1, -1, 2, 0.3)
+	if want := []int{10, 30, 50, 10, 50, 20}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got := NewRBTree[int]().Quantiles(0.5); got != nil {
+		t.Fatalf("empty tree: got %v", got)
+	}
+}
+
+func TestSample(t *testing.T) {
+	r := rand.New(rand.NewSource(17))
+	if _, ok := NewRBTree[int]().Sample(r); ok {
+		t.Fatal("Sample on an empty tree")
+	}
+
+	rb, vals := randomTree(17, 20)
+	seen := make(map[int]bool)
+	for i := 0; i < 1000; i++ {
+		v, ok := rb.Sample(r)
+		if !ok || !slices.Contains(vals, v) {
+			t.Fatalf("Sample returned %d, %t, which is not in the tree", v, ok)
+		}
+		seen[v] = true
+	}
+	// with 1000 draws from 20 nodes, every value is all but certain to come up
+	for _, v := range vals {
+		if !seen[v] {
+			t.Fatalf("Sample never returned %d", v)
+		}
+	}
+}
+
+func TestSampleK(t *testing.T) {
+	rb, vals := randomTree(13, 100)
+	r := rand.New(rand.NewSource(13))
+
+	for _, k := range []int{0, 1, 10, 100, 150} {
+		got := rb.SampleK(k, r)
+		if len(got) != min(k, len(vals)) || !slices.IsSorted(got) {
+			t.Fatalf("SampleK(%d): got %v", k, got)
+		}
+		for _, v := range got {
+			if !slices.Contains(vals, v) {
+				t.Fatalf("SampleK(%d) returned %d, which is not in the tree", k, v)
+			}
+		}
+	}
+}
+
+func TestLeafDepthRange(t *testing.T) {
+	if lo, hi := NewRBTree[int]().LeafDepthRange(); lo != 0 || hi != 0 {
+		t.Fatalf("empty tree: got %d, %d", lo, hi)
+	}
+
+	for _, cfg := range treeConfigs {
+		rb := NewRBTree[int](cfg.opts...)
+		for i := 0; i < 1000; i++ {
+			rb.Insert(i)
+		}
+
+		lo, hi := rb.LeafDepthRange()
+		if hi != rb.Height() || lo < 1 || hi > 2*lo {
+			t.Fatalf("%s: got %d, %d for a tree of height %d", cfg.name, lo, hi, rb.Height())
+		}
+	}
+}
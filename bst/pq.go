@@ -0,0 +1,78 @@
+package bst
+
+import "cmp"
+
+// TreePQ is a min priority queue view of an RBTree.
+// Unlike container/heap, the underlying tree keeps full ordering,
+// so it can still be queried or iterated while being used as a queue.
+// Push and Pop take O(log n) time, while Peek and Len take O(1), since the tree caches its minimum.
+type TreePQ[T any] struct {
+	tree *RBTree[T]
+}
+
+// Returns a priority queue backed by this tree. Changes through either are visible in both.
+func (rb *RBTree[T]) AsPriorityQueue() *TreePQ[T] {
+	return &TreePQ[T]{tree: rb}
+}
+
+func (pq *TreePQ[T]) Len() int {
+	return pq.tree.Len()
+}
+
+// Adds a value to the queue. Duplicates are allowed.
+func (pq *TreePQ[T]) Push(val T) {
+	pq.tree.Insert(val)
+}
+
+// Removes and returns the smallest value. Returns ErrEmptyTree if the queue is empty.
+func (pq *TreePQ[T]) Pop() (T, error) {
+	return pq.tree.PopMin()
+}
+
+// Returns the smallest value without removing it, or false if the queue is empty.
+func (pq *TreePQ[T]) Peek() (T, bool) {
+	return pq.tree.Min()
+}
+
+// DEPQ is a double-ended priority queue, which can remove both its smallest and largest values efficiently.
+// A binary heap can only do this for one end, but the tree keeps both ends in reach.
+// Duplicates are allowed. Push, PopMin and PopMax take O(log n) time, while PeekMin, PeekMax and Len
+// take O(1), since the tree caches its minimum and maximum.
+type DEPQ[T cmp.Ordered] struct {
+	tree *RBTree[T]
+}
+
+func NewDEPQ[T cmp.Ordered]() *DEPQ[T] {
+	return &DEPQ[T]{
+		tree: NewRBTree[T](),
+	}
+}
+
+func (q *DEPQ[T]) Len() int {
+	return q.tree.Len()
+}
+
+// Adds a value to the queue. Duplicates are allowed.
+func (q *DEPQ[T]) Push(val T) {
+	q.tree.Insert(val)
+}
+
+// Removes and returns the smallest value. Returns ErrEmptyTree if the queue is empty.
+func (q *DEPQ[T]) PopMin() (T, error) {
+	return q.tree.PopMin()
+}
+
+// Removes and returns the largest value. Returns ErrEmptyTree if the queue is empty.
+func (q *DEPQ[T]) PopMax() (T, error) {
+	return q.tree.PopMax()
+}
+
+// Returns the smallest value without removing it, or false if the queue is empty.
+func (q *DEPQ[T]) PeekMin() (T, bool) {
+	return q.tree.Min()
+}
+
+// Returns the largest value without removing it, or false if the queue is empty.
+func (q *DEPQ[T]) PeekMax() (T, bool) {
+	return q.tree.Max()
+}
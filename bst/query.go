@@ -0,0 +1,498 @@
+package bst
+
+import (
+	"context"
+	"slices"
+)
+
+// Returns the value stored in nd, or false if nd is nil.
+func valueOf[T any](nd *node[T]) (T, bool) {
+	if nd == nil {
+		var zero T
+		return zero, false
+	}
+	return nd.value, true
+}
+
+// Returns the smallest value in the tree, or false if the tree is empty.
+// Takes O(1) time, since the tree keeps track of its first and last nodes.
+func (rb *RBTree[T]) Min() (T, bool) {
+	return valueOf(rb.minNode())
+}
+
+// Returns the largest value in the tree, or false if the tree is empty. Takes O(1) time.
+func (rb *RBTree[T]) Max() (T, bool) {
+	return valueOf(rb.maxNode())
+}
+
+// Returns the smallest value strictly greater than val, or false if there is none.
+// val itself need not exist in the tree.
+func (rb *RBTree[T]) Successor(val T) (T, bool) {
+	return valueOf(rb.higherNode(val))
+}
+
+// Returns the largest value strictly less than val, or false if there is none.
+// val itself need not exist in the tree.
+func (rb *RBTree[T]) Predecessor(val T) (T, bool) {
+	return valueOf(rb.lowerNode(val))
+}
+
+// Returns the successor of val, wrapping around to the minimum when there is no successor.
+// This allows round-robin iteration over the values starting anywhere.
+// Returns false only if the tree is empty.
+func (rb *RBTree[T]) NextWrap(val T) (T, bool) {
+	if nd := rb.higherNode(val); nd != nil {
+		return nd.value, true
+	}
+	return rb.Min()
+}
+
+// Returns the predecessor of val, wrapping around to the maximum when there is no predecessor.
+// Returns false only if the tree is empty.
+func (rb *RBTree[T]) PrevWrap(val T) (T, bool) {
+	if nd := rb.lowerNode(val); nd != nil {
+		return nd.value, true
+	}
+	return rb.Max()
+}
+
+// Returns true if every given value exists in the tree. Returns true if no values are given.
+// Stops at the first missing value. Takes O(m log n) time for m values.
+func (rb *RBTree[T]) ExistsAll(vals ...T) bool {
+	for _, v := range vals {
+		if rb.findNode(v) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns true if at least one of the given values exists in the tree. Returns false if no values are given.
+// Stops at the first value found. Takes O(m log n) time for m values.
+func (rb *RBTree[T]) ExistsAny(vals ...T) bool {
+	for _, v := range vals {
+		if rb.findNode(v) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns whether each of the given values exists in the tree. The i-th result is for vals[i],
+// so the results are in the order of vals, even though the values are sorted internally.
+// Instead of searching for each value, the sorted values are merged with the tree in a single walk,
+// which takes O(n + m log m) time for m values. vals is not modified.
+func (rb *RBTree[T]) ContainsEach(vals []T) []bool {
+	order := make([]int, len(vals))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(i, j int) int {
+		return rb.cmp(vals[i], vals[j])
+	})
+
+	found := make([]bool, len(vals))
+	nd := rb.minNode()
+
+	for _, i := range order {
+		for nd != nil && rb.cmp(nd.value, vals[i]) < 0 {
+			nd = nd.successor()
+		}
+		if nd == nil {
+			break
+		}
+		found[i] = rb.cmp(nd.value, vals[i]) == 0
+	}
+	return found
+}
+
+// Returns the largest value <= val, or false if there is none.
+// With a custom comparator, "largest" and "<=" follow the comparator's ordering.
+func (rb *RBTree[T]) Floor(val T) (T, bool) {
+	return valueOf(rb.floorNode(val))
+}
+
+// Returns the smallest value >= val, or false if there is none.
+// With a custom comparator, "smallest" and ">=" follow the comparator's ordering.
+func (rb *RBTree[T]) Ceiling(val T) (T, bool) {
+	return valueOf(rb.ceilingNode(val))
+}
+
+// Returns the values in [lo, hi] in ascending order. Both bounds are inclusive.
+// Returns nil if lo comes after hi in the tree's ordering.
+//
+// Like all range methods, "ascending" and "within [lo, hi]" follow the tree's comparator,
+// so with a descending comparator, lo must be the larger value: Range(9, 3) returns 9, ..., 3,
+// a contiguous run of the tree's order, while Range(3, 9) returns nil.
+func (rb *RBTree[T]) Range(lo, hi T) []T {
+	var values []T
+	rb.RangeFunc(lo, hi, func(v T) bool {
+		values = append(values, v)
+		return true
+	})
+	return values
+}
+
+// Replaces every value in [lo, hi] with f applied to it. Both bounds are inclusive, and follow the tree's ordering like Range.
+// Since f may change where a value belongs, the values in the range are removed first, and then the results
+// are inserted, so they end up in the right place, within the range or not. Values outside the range are untouched.
+// Observers see a deletion and an insertion for each value, and the duplicate policy applies to the results.
+// Takes O(log n + k log n) time for k values in the range.
+func (rb *RBTree[T]) ApplyRange(lo, hi T, f func(T) T) {
+	for _, v := range rb.PopRange(lo, hi) {
+		rb.Insert(f(v))
+	}
+}
+
+// Calls fn for each value in [lo, hi] in ascending order, stopping early if fn returns false.
+// Both bounds are inclusive, and follow the tree's ordering like Range. fn must not modify the tree.
+func (rb *RBTree[T]) RangeFunc(lo, hi T, fn func(T) bool) {
+	gen := rb.gen
+	for nd := rb.ceilingNode(lo); nd != nil && rb.cmp(nd.value, hi) <= 0; nd = nd.successor() {
+		if !fn(nd.value) {
+			return
+		}
+		rb.checkGen(gen)
+	}
+}
+
+// Returns the number of values in [lo, hi]. Both bounds are inclusive, and follow the tree's ordering like Range.
+// Returns 0 if lo comes after hi. Takes O(log n) time using the subtree sizes, regardless of the count.
+func (rb *RBTree[T]) RangeCount(lo, hi T) int {
+	return max(rb.countLess(hi, true)-rb.countLess(lo, false), 0)
+}
+
+// Removes the values in [lo, hi] from the tree and returns them in ascending order.
+// Both bounds are inclusive, and follow the tree's ordering like Range.
+// Returns nil and leaves the tree unchanged if lo comes after hi in the tree's ordering.
+// The range is searched only once: the nodes found are then unlinked directly, without searching for their values again.
+// Takes O(log n + k log n) time for k removed values.
+func (rb *RBTree[T]) PopRange(lo, hi T) []T {
+	var nodes []*node[T]
+	for nd := rb.ceilingNode(lo); nd != nil && rb.cmp(nd.value, hi) <= 0; nd = nd.successor() {
+		nodes = append(nodes, nd)
+	}
+
+	var values []T
+	for _, nd := range nodes {
+		// deleteNode relinks nodes rather than moving values between them, so the remaining pointers stay valid
+		rb.deleteNode(nd)
+		values = append(values, nd.value)
+	}
+	return values
+}
+
+// Removes the values in [lo, hi] for which pred returns true, and returns the number removed.
+// Both bounds are inclusive, and follow the tree's ordering like Range. Only the nodes in the range are visited,
+// and the matching ones are then unlinked directly like in PopRange. pred must not modify the tree.
+// Takes O(log n + k + m log n) time, where k is the number of values in the range and m the number removed.
+func (rb *RBTree[T]) DeleteRangeFunc(lo, hi T, pred func(T) bool) int {
+	var nodes []*node[T]
+	for nd := rb.ceilingNode(lo); nd != nil && rb.cmp(nd.value, hi) <= 0; nd = nd.successor() {
+		if pred(nd.value) {
+			nodes = append(nodes, nd)
+		}
+	}
+
+	for _, nd := range nodes {
+		rb.deleteNode(nd)
+	}
+	return len(nodes)
+}
+
+// Same as DeleteRangeFunc, but stops early if ctx is cancelled, returning the number removed so far and ctx.Err().
+// The context is checked before the first node is visited and then periodically, both while collecting the
+// matching nodes and while removing them. On cancellation, the values removed so far stay removed,
+// and the tree remains valid with an accurate Len.
+func (rb *RBTree[T]) DeleteRangeFuncCtx(ctx context.Context, lo, hi T, pred func(T) bool) (int, error) {
+	var nodes []*node[T]
+	i := 0
+	for nd := rb.ceilingNode(lo); nd != nil && rb.cmp(nd.value, hi) <= 0; nd, i = nd.successor(), i+1 {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+		}
+		if pred(nd.value) {
+			nodes = append(nodes, nd)
+		}
+	}
+
+	for i, nd := range nodes {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return i, err
+			}
+		}
+		rb.deleteNode(nd)
+	}
+	return len(nodes), nil
+}
+
+// Removes and returns the smallest value. Returns ErrEmptyTree if the tree is empty.
+func (rb *RBTree[T]) PopMin() (T, error) {
+	nd := rb.minNode()
+	if nd == nil {
+		var zero T
+		return zero, ErrEmptyTree
+	}
+	rb.deleteNode(nd)
+	return nd.value, nil
+}
+
+// Removes and returns the largest value. Returns ErrEmptyTree if the tree is empty.
+func (rb *RBTree[T]) PopMax() (T, error) {
+	nd := rb.maxNode()
+	if nd == nil {
+		var zero T
+		return zero, ErrEmptyTree
+	}
+	rb.deleteNode(nd)
+	return nd.value, nil
+}
+
+// Returns the values just before and just after val in ascending order.
+// If val exists, these are the predecessor and successor of the first node equal to val,
+// so with duplicates, next can be equal to val.
+// If val does not exist, these are the values around the position where val would be inserted,
+// i.e. the largest value < val and the smallest value > val.
+// The ok flags are false when there is no such value.
+func (rb *RBTree[T]) Neighbors(val T) (prev T, prevOK bool, next T, nextOK bool) {
+	// first node with value >= val
+	nd := rb.ceilingNode(val)
+
+	var prevNd, nextNd *node[T]
+	if nd == nil {
+		// every value is < val
+		prevNd = rb.maxNode()
+	} else {
+		prevNd = nd.predecessor()
+		if rb.cmp(nd.value, val) == 0 {
+			nextNd = nd.successor()
+		} else {
+			nextNd = nd
+		}
+	}
+
+	prev, prevOK = valueOf(prevNd)
+	next, nextOK = valueOf(nextNd)
+	return prev, prevOK, next, nextOK
+}
+
+// Returns the largest value strictly less than val and the smallest value strictly greater than val,
+// whether or not val itself exists. These bracket the gap where val is, or would be inserted.
+// Unlike Neighbors, neither side is anchored at a node equal to val.
+// A single descent finds both, until it reaches a node equal to val, below which the two bounds
+// can only be in its left and right subtrees respectively. Takes O(log n) time.
+func (rb *RBTree[T]) Bracket(val T) (lo T, loOK bool, hi T, hiOK bool) {
+	var loNd, hiNd *node[T]
+	nd := rb.root
+
+	for nd != nil {
+		c := rb.cmp(nd.value, val)
+		if c < 0 {
+			loNd = nd
+			nd = nd.right
+		} else if c > 0 {
+			hiNd = nd
+			nd = nd.left
+		} else {
+			break
+		}
+	}
+
+	if nd != nil {
+		// equal values might be on both sides of nd, so continue separately in each subtree
+		for x := nd.left; x != nil; {
+			if rb.cmp(x.value, val) < 0 {
+				loNd = x
+				x = x.right
+			} else {
+				x = x.left
+			}
+		}
+
+		for x := nd.right; x != nil; {
+			if rb.cmp(x.value, val) > 0 {
+				hiNd = x
+				x = x.left
+			} else {
+				x = x.right
+			}
+		}
+	}
+
+	lo, loOK = valueOf(loNd)
+	hi, hiOK = valueOf(hiNd)
+	return lo, loOK, hi, hiOK
+}
+
+// Returns true if both a and b exist, and no stored value lies strictly between them,
+// i.e. they are consecutive distinct values in sorted order. The order of a and b doesn't matter.
+// Returns false if either is absent, or if they are equal. Takes O(log n) time.
+func (rb *RBTree[T]) AreAdjacent(a, b T) bool {
+	c := rb.cmp(a, b)
+	if c == 0 {
+		return false
+	}
+	if c > 0 {
+		a, b = b, a
+	}
+
+	// the last copy of a, whose successor is the next distinct value
+	nd := rb.floorNode(a)
+	if nd == nil || rb.cmp(nd.value, a) != 0 {
+		return false
+	}
+
+	next := nd.successor()
+	return next != nil && rb.cmp(next.value, b) == 0
+}
+
+// Returns up to before values less than center, every value equal to center, and up to after values
+// greater than center, all in ascending order. This is the "show N rows around the selected one" pattern.
+// If center does not exist, the window is anchored at its floor and ceiling, i.e. around where it would be.
+// Takes O(log n + k) time, where k is the size of the window.
+func (rb *RBTree[T]) Window(center T, before, after int) []T {
+	var values []T
+
+	// walk back from the last value less than center, so that values equal to it are left to the loop below, then reverse
+	for nd := rb.lowerNode(center); nd != nil && len(values) < before; nd = nd.predecessor() {
+		values = append(values, nd.value)
+	}
+	slices.Reverse(values)
+
+	for nd := rb.ceilingNode(center); nd != nil && rb.cmp(nd.value, center) == 0; nd = nd.successor() {
+		values = append(values, nd.value)
+	}
+
+	for nd, n := rb.higherNode(center), 0; nd != nil && n < after; nd, n = nd.successor(), n+1 {
+		values = append(values, nd.value)
+	}
+
+	return values
+}
+
+// Returns true if val is equal to the smallest value in the tree.
+// Returns false if val is not present, including when the tree is empty.
+func (rb *RBTree[T]) IsMin(val T) bool {
+	nd := rb.minNode()
+	return nd != nil && rb.cmp(nd.value, val) == 0
+}
+
+// Returns true if val is equal to the largest value in the tree.
+// Returns false if val is not present, including when the tree is empty.
+func (rb *RBTree[T]) IsMax(val T) bool {
+	nd := rb.maxNode()
+	return nd != nil && rb.cmp(nd.value, val) == 0
+}
+
+// Inserts val, and then deletes the smallest values while Len exceeds capacity. Returns true if val is still stored.
+// This keeps the capacity largest values seen so far, i.e. a running top-k, so val ends up stored
+// only if it is greater than the minimum of a full tree.
+// On a tie with the minimum, the node deleted is the first one in ascending order: without WithInsertionOrder
+// that is the new node, so val is not stored, and with it, the older equal value is deleted instead.
+// If the duplicate policy doesn't add a node for val, nothing is deleted, and true is returned only if val
+// replaced the equal value. Takes O(log n) time per value inserted or deleted.
+func (rb *RBTree[T]) InsertIfGreaterThanMin(val T, capacity int) bool {
+	return rb.insertBounded(val, capacity, nil)
+}
+
+// Same as InsertIfGreaterThanMin, but also calls evict, if not nil, with each deleted value right after deleting it.
+func (rb *RBTree[T]) insertBounded(val T, capacity int, evict func(T)) bool {
+	p, existing := rb.locate(val)
+	if existing != nil {
+		rb.resolveDuplicate(existing, val)
+		return rb.dups == ReplaceDuplicates
+	}
+
+	nd := rb.attach(p, val)
+	stored := true
+
+	for rb.len > max(capacity, 0) {
+		m := rb.minNode()
+		rb.deleteNode(m)
+		if m == nd {
+			stored = false
+		}
+		if evict != nil {
+			evict(m.value)
+		}
+	}
+
+	return stored
+}
+
+// Removes the smallest value without returning it. Returns false if the tree is empty.
+func (rb *RBTree[T]) DeleteMin() bool {
+	nd := rb.minNode()
+	if nd == nil {
+		return false
+	}
+	rb.deleteNode(nd)
+	return true
+}
+
+// Removes the largest value without returning it. Returns false if the tree is empty.
+func (rb *RBTree[T]) DeleteMax() bool {
+	nd := rb.maxNode()
+	if nd == nil {
+		return false
+	}
+	rb.deleteNode(nd)
+	return true
+}
+
+// Returns the first value >= x, like lower_bound of C++ std::set. Same as Ceiling.
+// Returns false if there is no such value.
+func (rb *RBTree[T]) LowerBound(x T) (T, bool) {
+	return valueOf(rb.ceilingNode(x))
+}
+
+// Returns the first value > x, like upper_bound of C++ std::set. Same as Successor.
+// Returns false if there is no such value.
+func (rb *RBTree[T]) UpperBound(x T) (T, bool) {
+	return valueOf(rb.higherNode(x))
+}
+
+// Returns up to limit values in ascending order, starting after the token, and the token for the next page.
+// A nil after starts from the smallest value. The next token is nil once there are no more values.
+// Pass it back as after to continue the scan:
+//
+//	for values, next := rb.Scan(nil, 100); ; values, next = rb.Scan(next, 100) {
+//		// use values
+//		if next == nil {
+//			break
+//		}
+//	}
+//
+// The token holds a value, not a position, so the scan is stable across modifications between pages:
+// each page starts at the first value strictly greater than the token, even if the token value has been deleted.
+// Since duplicates can't be told apart by a value token, a page never ends in the middle of a run of equal values,
+// and may hold more than limit values to include all of them. If limit is not positive, returns nil and after.
+// Takes O(log n + k) time for a page of k values.
+func (rb *RBTree[T]) Scan(after *T, limit int) (values []T, next *T) {
+	if limit <= 0 {
+		return nil, after
+	}
+
+	var nd *node[T]
+	if after == nil {
+		nd = rb.minNode()
+	} else {
+		nd = rb.higherNode(*after)
+	}
+
+	for ; nd != nil; nd = nd.successor() {
+		if len(values) >= limit && rb.cmp(nd.value, values[len(values)-1]) != 0 {
+			break
+		}
+		values = append(values, nd.value)
+	}
+
+	if nd == nil {
+		return values, nil
+	}
+	last := values[len(values)-1]
+	return values, &last
+}
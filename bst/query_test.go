@@ -0,0 +1,436 @@
+package bst
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// Returns a tree of random values in [0, 100), many of them duplicated, along with the values in ascending order.
+func randomTree(seed int64, n int) (*RBTree[int], []int) {
+	r := rand.New(rand.NewSource(seed))
+	rb := NewRBTree[int]()
+	vals := make([]int, n)
+	for i := range vals {
+		vals[i] = r.Intn(100)
+		rb.Insert(vals[i])
+	}
+	slices.Sort(vals)
+	return rb, vals
+}
+
+// Returns the values of sorted that satisfy pred, in order.
+func filter(sorted []int, pred func(int) bool) []int {
+	var res []int
+	for _, v := range sorted {
+		if pred(v) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// Returns the first value of s, or false if s is empty.
+func firstOf(s []int) (int, bool) {
+	if len(s) == 0 {
+		return 0, false
+	}
+	return s[0], true
+}
+
+// Returns the last value of s, or false if s is empty.
+func lastOf(s []int) (int, bool) {
+	if len(s) == 0 {
+		return 0, false
+	}
+	return s[len(s)-1], true
+}
+
+// Fails the test unless the lookup name(v) returned want, wantOK.
+func checkLookup(t *testing.T, name string, v int, got int, gotOK bool, want int, wantOK bool) {
+	t.Helper()
+	if gotOK != wantOK || got != want {
+		t.Fatalf("%s(%d): got %d, %t, want %d, %t", name, v, got, gotOK, want, wantOK)
+	}
+}
+
+func TestPredecessorSuccessor(t *testing.T) {
+	rb, vals := randomTree(5, 60)
+
+	for v := -2; v < 103; v++ {
+		got, ok := rb.Predecessor(v)
+		want, wantOK := lastOf(filter(vals, func(x int) bool { return x < v }))
+		checkLookup(t, "Predecessor", v, got, ok, want, wantOK)
+
+		got, ok = rb.Successor(v)
+		want, wantOK = firstOf(filter(vals, func(x int) bool { return x > v }))
+		checkLookup(t, "Successor", v, got, ok, want, wantOK)
+	}
+}
+
+func TestFloorCeiling(t *testing.T) {
+	rb, vals := randomTree(5, 60)
+
+	for v := -2; v < 103; v++ {
+		got, ok := rb.Floor(v)
+		want, wantOK := lastOf(filter(vals, func(x int) bool { return x <= v }))
+		checkLookup(t, "Floor", v, got, ok, want, wantOK)
+
+		got, ok = rb.Ceiling(v)
+		want, wantOK = firstOf(filter(vals, func(x int) bool { return x >= v }))
+		checkLookup(t, "Ceiling", v, got, ok, want, wantOK)
+	}
+}
+
+func TestFloorCeilingDescending(t *testing.T) {
+	rb := NewRBTreeFunc(func(a, b int) int { return b - a })
+	rb.InsertAll(10, 20, 30)
+
+	// in the tree's order 30, 20, 10, the floor of 25 is the earlier neighbor, which is the larger value
+	if got, ok := rb.Floor(25); !ok || got != 30 {
+		t.Fatalf("Floor(25): got %d, %t, want 30", got, ok)
+	}
+	if got, ok := rb.Ceiling(25); !ok || got != 20 {
+		t.Fatalf("Ceiling(25): got %d, %t, want 20", got, ok)
+	}
+	if got, ok := rb.Floor(20); !ok || got != 20 {
+		t.Fatalf("Floor(20): got %d, %t, want 20", got, ok)
+	}
+	if _, ok := rb.Floor(35); ok {
+		t.Fatal("Floor(35): found a value before the first one")
+	}
+}
+
+func TestLowerUpperBound(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{2, 4, 4, 4, 8})
+
+	tests := []struct {
+		x            int
+		lower, upper int
+		lowerOK      bool
+		upperOK      bool
+	}{
+		{1, 2, 2, true, true},   // below the minimum
+		{2, 2, 4, true, true},   // the minimum
+		{4, 4, 8, true, true},   // duplicates
+		{5, 8, 8, true, true},   // missing
+		{8, 8, 0, true, false},  // the maximum
+		{9, 0, 0, false, false}, // above the maximum
+	}
+	for _, tt := range tests {
+		got, ok := rb.LowerBound(tt.x)
+		checkLookup(t, "LowerBound", tt.x, got, ok, tt.lower, tt.lowerOK)
+		got, ok = rb.UpperBound(tt.x)
+		checkLookup(t, "UpperBound", tt.x, got, ok, tt.upper, tt.upperOK)
+	}
+}
+
+func TestBracket(t *testing.T) {
+	rb, vals := randomTree(5, 60)
+
+	for v := -2; v < 103; v++ {
+		lo, loOK, hi, hiOK := rb.Bracket(v)
+		want, wantOK := lastOf(filter(vals, func(x int) bool { return x < v }))
+		checkLookup(t, "Bracket", v, lo, loOK, want, wantOK)
+		want, wantOK = firstOf(filter(vals, func(x int) bool { return x > v }))
+		checkLookup(t, "Bracket", v, hi, hiOK, want, wantOK)
+	}
+}
+
+func TestAreAdjacent(t *testing.T) {
+	rb, vals := randomTree(5, 60)
+
+	for v := -2; v < 103; v++ {
+		adjacent := slices.Contains(vals, v) && slices.Contains(vals, v+1)
+		if rb.AreAdjacent(v, v+1) != adjacent || rb.AreAdjacent(v+1, v) != adjacent {
+			t.Fatalf("AreAdjacent(%d, %d) is not %t", v, v+1, adjacent)
+		}
+	}
+
+	if rb.AreAdjacent(vals[0], vals[0]) {
+		t.Fatal("a value is adjacent to itself")
+	}
+}
+
+func TestRange(t *testing.T) {
+	rb, vals := randomTree(6, 80)
+
+	for lo := -5; lo < 105; lo += 7 {
+		for hi := lo - 10; hi < 110; hi += 13 {
+			want := filter(vals, func(x int) bool { return lo <= x && x <= hi })
+			if got := rb.Range(lo, hi); !slices.Equal(got, want) {
+				t.Fatalf("Range(%d, %d): got %v, want %v", lo, hi, got, want)
+			}
+		}
+	}
+}
+
+func TestRangeCountFunc(t *testing.T) {
+	rb, vals := randomTree(6, 80)
+
+	for lo := -5; lo < 105; lo += 7 {
+		for hi := lo - 10; hi < 110; hi += 13 {
+			want := filter(vals, func(x int) bool { return lo <= x && x <= hi })
+			if got := rb.RangeCount(lo, hi); got != len(want) {
+				t.Fatalf("RangeCount(%d, %d): got %d, want %d", lo, hi, got, len(want))
+			}
+		}
+	}
+
+	var firstThree []int
+	rb.RangeFunc(10, 90, func(v int) bool {
+		firstThree = append(firstThree, v)
+		return len(firstThree) < 3
+	})
+	if want := filter(vals, func(x int) bool { return x >= 10 })[:3]; !slices.Equal(firstThree, want) {
+		t.Fatalf("RangeFunc stopping early: got %v, want %v", firstThree, want)
+	}
+}
+
+func TestRangeDescending(t *testing.T) {
+	rb := NewRBTreeFunc(func(a, b int) int { return b - a })
+	rb.InsertAll(3, 9, 1, 7, 5, 11, 5)
+
+	// lo is the larger value, and the result follows the tree's order, which is descending
+	if got, want := rb.Range(9, 3), []int{9, 7, 5, 5, 3}; !slices.Equal(got, want) {
+		t.Fatalf("Range(9, 3): got %v, want %v", got, want)
+	}
+	if got := rb.Range(3, 9); got != nil {
+		t.Fatalf("Range(3, 9): got %v, want nil", got)
+	}
+
+	// the result is a contiguous run of the full traversal
+	all := rb.GetValues()
+	got := rb.Range(10, 4)
+	i := slices.Index(all, got[0])
+	if i < 0 || !slices.Equal(all[i:i+len(got)], got) {
+		t.Fatalf("Range(10, 4) = %v is not a contiguous run of %v", got, all)
+	}
+}
+
+func TestPopRange(t *testing.T) {
+	rb, vals := randomTree(7, 100)
+
+	popped := rb.PopRange(20, 40)
+	if want := filter(vals, func(x int) bool { return 20 <= x && x <= 40 }); !slices.Equal(popped, want) {
+		t.Fatalf("got %v, want %v", popped, want)
+	}
+	checkValues(t, rb, filter(vals, func(x int) bool { return x < 20 || x > 40 }))
+
+	if got := rb.PopRange(60, 50); got != nil {
+		t.Fatalf("inverted range: got %v", got)
+	}
+}
+
+func TestDeleteRangeFunc(t *testing.T) {
+	rb, vals := randomTree(8, 100)
+
+	even := func(x int) bool { return x%2 == 0 }
+	n := rb.DeleteRangeFunc(10, 20, even)
+
+	want := filter(vals, func(x int) bool { return x < 10 || x > 20 || !even(x) })
+	if n != len(vals)-len(want) {
+		t.Fatalf("got count %d, want %d", n, len(vals)-len(want))
+	}
+	checkValues(t, rb, want)
+}
+
+func TestDeleteRangeFuncCtx(t *testing.T) {
+	rb, vals := randomTree(9, 100)
+
+	even := func(x int) bool { return x%2 == 0 }
+	n, err := rb.DeleteRangeFuncCtx(context.Background(), 10, 20, even)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := filter(vals, func(x int) bool { return x < 10 || x > 20 || !even(x) })
+	if n != len(vals)-len(want) {
+		t.Fatalf("got count %d, want %d", n, len(vals)-len(want))
+	}
+	checkValues(t, rb, want)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if n, err := rb.DeleteRangeFuncCtx(ctx, 0, 1000, even); n != 0 || !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled: got %d, %v", n, err)
+	}
+	checkValues(t, rb, want)
+}
+
+func TestApplyRange(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{1, 2, 3, 4, 5})
+	rb.ApplyRange(2, 3, func(v int) int { return v * 10 })
+	checkValues(t, rb, []int{1, 4, 5, 20, 30})
+}
+
+func TestExistsAllAny(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{1, 3, 3, 5})
+
+	tests := []struct {
+		vals     []int
+		all, any bool
+	}{
+		{nil, true, false},
+		{[]int{3}, true, true},
+		{[]int{1, 3, 5}, true, true},
+		{[]int{1, 2}, false, true},
+		{[]int{2, 4}, false, false},
+	}
+	for _, tt := range tests {
+		if got := rb.ExistsAll(tt.vals...); got != tt.all {
+			t.Errorf("ExistsAll(%v): got %t", tt.vals, got)
+		}
+		if got := rb.ExistsAny(tt.vals...); got != tt.any {
+			t.Errorf("ExistsAny(%v): got %t", tt.vals, got)
+		}
+	}
+
+	empty := NewRBTree[int]()
+	if !empty.ExistsAll() || empty.ExistsAll(1) || empty.ExistsAny() || empty.ExistsAny(1) {
+		t.Error("wrong result on an empty tree")
+	}
+}
+
+func TestContainsEach(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{1, 3, 5, 5, 7})
+
+	got := rb.ContainsEach([]int{7, 2, 5, 5, 9, 1, 0})
+	want := []bool{true, false, true, true, false, true, false}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got := rb.ContainsEach(nil); len(got) != 0 {
+		t.Fatalf("no values: got %v", got)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	rb := NewRBTree[int]()
+	rb.InsertAll(1, 2, 4, 4, 6, 8, 9)
+
+	tests := []struct {
+		center, before, after int
+		want                  []int
+	}{
+		{4, 1, 1, []int{2, 4, 4, 6}},
+		{4, 5, 5, []int{1, 2, 4, 4, 6, 8, 9}},
+		{4, 0, 0, []int{4, 4}},
+		{5, 2, 1, []int{4, 4, 6}},
+		{0, 2, 2, []int{1, 2}},
+		{10, 2, 2, []int{8, 9}},
+	}
+	for _, tt := range tests {
+		if got := rb.Window(tt.center, tt.before, tt.after); !slices.Equal(got, tt.want) {
+			t.Errorf("Window(%d, %d, %d): got %v, want %v", tt.center, tt.before, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	rb, vals := randomTree(9, 200)
+
+	var all []int
+	pages := 0
+	for page, next := rb.Scan(nil, 7); ; page, next = rb.Scan(next, 7) {
+		if len(page) < 7 && next != nil {
+			t.Fatalf("short page %v before the end", page)
+		}
+		all = append(all, page...)
+		pages++
+		if next == nil {
+			break
+		}
+	}
+
+	if !slices.Equal(all, vals) {
+		t.Fatalf("scanned %v, want %v", all, vals)
+	}
+	if pages < len(vals)/14 {
+		t.Fatalf("scanned %d values in only %d pages", len(vals), pages)
+	}
+}
+
+func TestIsMinMax(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{2, 2, 5, 9, 9})
+
+	for v := 0; v < 11; v++ {
+		if got := rb.IsMin(v); got != (v == 2) {
+			t.Errorf("IsMin(%d): got %t", v, got)
+		}
+		if got := rb.IsMax(v); got != (v == 9) {
+			t.Errorf("IsMax(%d): got %t", v, got)
+		}
+	}
+
+	if err := rb.Delete(9); err != nil {
+		t.Fatal(err)
+	}
+	if !rb.IsMax(9) {
+		t.Error("IsMax(9) after deleting one of two nines")
+	}
+	if err := rb.Delete(9); err != nil {
+		t.Fatal(err)
+	}
+	if rb.IsMax(9) || !rb.IsMax(5) {
+		t.Error("IsMax didn't follow the deletion of the maximum")
+	}
+
+	if empty := NewRBTree[int](); empty.IsMin(0) || empty.IsMax(0) {
+		t.Error("an empty tree has a minimum or maximum")
+	}
+}
+
+func TestInsertIfGreaterThanMin(t *testing.T) {
+	rb := NewRBTree[int]()
+	for _, v := range []int{5, 1, 7, 3, 9} {
+		rb.InsertIfGreaterThanMin(v, 3)
+	}
+	checkValues(t, rb, []int{5, 7, 9})
+
+	if rb.InsertIfGreaterThanMin(2, 3) {
+		t.Fatal("a value below the minimum of a full tree was stored")
+	}
+	if !rb.InsertIfGreaterThanMin(8, 3) {
+		t.Fatal("a value above the minimum of a full tree was not stored")
+	}
+	checkValues(t, rb, []int{7, 8, 9})
+}
+
+func TestNeighbors(t *testing.T) {
+	rb := NewRBTree[int]()
+	rb.InsertAll(1, 3, 3, 5)
+
+	tests := []struct {
+		val            int
+		prev, next     int
+		prevOK, nextOK bool
+	}{
+		{3, 1, 3, true, true},
+		{4, 3, 5, true, true},
+		{0, 0, 1, false, true},
+		{5, 3, 0, true, false},
+	}
+	for _, tt := range tests {
+		prev, prevOK, next, nextOK := rb.Neighbors(tt.val)
+		if prevOK != tt.prevOK || nextOK != tt.nextOK || (prevOK && prev != tt.prev) || (nextOK && next != tt.next) {
+			t.Errorf("Neighbors(%d): got %d, %t, %d, %t", tt.val, prev, prevOK, next, nextOK)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	rb := NewRBTree[int]()
+	rb.InsertAll(1, 5, 9)
+
+	if got, _ := rb.NextWrap(9); got != 1 {
+		t.Errorf("NextWrap(9): got %d", got)
+	}
+	if got, _ := rb.PrevWrap(1); got != 9 {
+		t.Errorf("PrevWrap(1): got %d", got)
+	}
+	if _, ok := NewRBTree[int]().NextWrap(1); ok {
+		t.Error("NextWrap on an empty tree")
+	}
+}
@@ -2,23 +2,36 @@ package bst
 
 import (
 	"cmp"
+	"fmt"
 )
 
-type color int
+type color uint8
 
 const (
 	black color = 0
 	red   color = 1
 )
 
-type node[T cmp.Ordered] struct {
+type node[T any] struct {
 	left   *node[T]
 	right  *node[T]
 	parent *node[T]
 	clr    color
 	value  T
+	// number of nodes in the subtree rooted at this node
+	sz int
+	// number of nodes on the longest path from this node down to a leaf
+	ht int
+	// insertion sequence number, only stamped with WithInsertionOrder
+	seq uint64
 }
 
+// Returns the color of nd, which is black for nil.
+//
+// Leaves are nil pointers, not a sentinel node as in CLRS. A sentinel would have its parent field
+// written during deletion, so it couldn't be shared between trees, and a sentinel per tree would
+// prevent moving subtrees between trees, as Concat does. Instead, this and the accessors below are safe
+// to call on nil, which lets the fixup code treat nil like a black leaf without checking for it.
 func (nd *node[T]) color() color {
 	if nd == nil {
 		// nil pointers are considered black
@@ -27,6 +40,63 @@ func (nd *node[T]) color() color {
 	return nd.clr
 }
 
+// Sets the color of nd. Setting the color of a nil leaf is a no-op, since it is always black.
+func (nd *node[T]) setColor(c color) {
+	if nd != nil {
+		nd.clr = c
+	}
+}
+
+func (nd *node[T]) getLeft() *node[T] {
+	if nd == nil {
+		return nil
+	}
+	return nd.left
+}
+
+func (nd *node[T]) getRight() *node[T] {
+	if nd == nil {
+		return nil
+	}
+	return nd.right
+}
+
+func (nd *node[T]) size() int {
+	if nd == nil {
+		return 0
+	}
+	return nd.sz
+}
+
+func (nd *node[T]) height() int {
+	if nd == nil {
+		return 0
+	}
+	return nd.ht
+}
+
+// Recomputes the size and height of nd from its children. nd must be non-nil.
+func (nd *node[T]) update() {
+	nd.sz = nd.left.size() + nd.right.size() + 1
+	nd.ht = max(nd.left.height(), nd.right.height()) + 1
+}
+
+// Recomputes the size and height of nd from its children, and then its augmented data, if the tree has any.
+// nd must be non-nil.
+func (rb *RBTree[T]) update(nd *node[T]) {
+	nd.update()
+	if rb.augment != nil {
+		rb.augment(nd)
+	}
+}
+
+// Same as update, for nd and all its ancestors. nd may be nil.
+func (rb *RBTree[T]) updateUp(nd *node[T]) {
+	for ; nd != nil; nd = nd.parent {
+		rb.update(nd)
+	}
+}
+
 // BST is implemented using Red-Black Tree.
 // An RBTree has following properties
 //  1. All nodes are either red or black.
@@ -36,57 +106,225 @@ func (nd *node[T]) color() color {
 //  5. In any subtree, all simple paths from root of the subtree to leaves (nil nodes) contain the same number of black nodes.
 //  6. Corollary: Color of a single child must be red. If it were black, then property 5 would be violated.
 //     This means that a non-nil black node always has a non-nil sibling.
-type RBTree[T cmp.Ordered] struct {
+//
+// The balancing strategy can be changed with WithBalancing, in which case the color properties don't apply.
+//
+// An RBTree is not safe for concurrent use. Inserting or deleting while iterating over the tree with one of its
+// iterators makes the iterator panic at its next step, on a best-effort basis. Use SyncRBTree for concurrent use.
+type RBTree[T any] struct {
 	root *node[T]
 	len  int
+	// returns a negative number if a < b, zero if a == b and a positive number if a > b
+	cmp func(a, b T) int
+	// total number of rotations performed so far, for instrumentation
+	rotations int
+	// observer called after each insertion and deletion, if non-nil
+	onChange func(ChangeEvent[T])
+	// what Insert does with values equal to an existing one
+	dups DuplicatePolicy
+	// how the tree is kept balanced
+	balancing Balancing
+	// whether equal values are kept in insertion order, and the last sequence number stamped if so
+	insertionOrder bool
+	seq            uint64
+	// number of structural modifications so far, for detecting them during iteration
+	gen uint64
+	// first and last nodes in inorder traversal, or nil if the tree is empty
+	minNd, maxNd *node[T]
+	// recomputes data that wrappers like SumTree keep in the values, from the values of the children, or nil.
+	// It is called wherever the size and height are updated on insertion, deletion and rotation, but not
+	// by the bulk operations that relink nodes wholesale, which the wrappers must not use.
+	augment func(nd *node[T])
+}
+
+func NewRBTree[T cmp.Ordered](opts ...Option) *RBTree[T] {
+	return NewRBTreeFunc(cmp.Compare[T], opts...)
 }
 
-func NewRBTree[T cmp.Ordered]() *RBTree[T] {
-	return &RBTree[T]{}
+// Same as NewRBTree. Meant to be used along with WithBalancing, since the tree need not be a red-black tree:
+//
+//	tree := NewBST[int](WithBalancing(AVL))
+func NewBST[T cmp.Ordered](opts ...Option) *RBTree[T] {
+	return NewRBTree[T](opts...)
+}
+
+// Returns an empty tree ordered by the given comparison function, like the one accepted by slices.SortFunc.
+// Values for which cmp returns zero are considered equal, and all operations, including lookups,
+// deletions and range queries, follow this ordering. For example, cmp can order values in reverse,
+// or order pointers by a field of the values they point to:
+//
+//	tree := NewRBTreeFunc(func(a, b *Record) int { return cmp.Compare(a.Key, b.Key) })
+//
+// Equality is never decided by ==, so for pointer elements, Exists and Delete match any pointer
+// whose record has the same key, not just the identical pointer. Delete removes one such element,
+// which need not be the pointer that was passed in.
+func NewRBTreeFunc[T any](cmp func(a, b T) int, opts ...Option) *RBTree[T] {
+	rb := &RBTree[T]{
+		cmp: cmp,
+	}
+	rb.applyOptions(opts)
+	return rb
 }
 
 func (rb *RBTree[T]) Len() int {
 	return rb.len
 }
 
-// Insert a new node in the tree with the given value. Inserts even if the value already exists.
+// Insert a new node in the tree with the given value.
+// By default, inserts even if the value already exists. This can be changed with WithDuplicatePolicy.
 func (rb *RBTree[T]) Insert(val T) {
-	// insert new node as usual
+	p, existing := rb.locate(val)
+	if existing != nil {
+		rb.resolveDuplicate(existing, val)
+		return
+	}
 
+	rb.attach(p, val)
+}
+
+// Inserts val, which must be >= the maximum of the tree, as the new last node.
+// Instead of searching from the root, the node is attached directly to the cached maximum,
+// which makes ingesting ascending values faster, though restoring balance still takes O(log n) time.
+// A value equal to the maximum is inserted with Insert, so that it is placed and handled like any other duplicate.
+// Returns an error wrapping ErrOutOfOrder, without inserting, if val is less than the maximum.
+func (rb *RBTree[T]) Append(val T) error {
+	if rb.maxNd != nil {
+		c := rb.cmp(val, rb.maxNd.value)
+		if c < 0 {
+			return fmt.Errorf("append %v: less than maximum %v: %w", val, rb.maxNd.value, ErrOutOfOrder)
+		}
+		if c == 0 {
+			rb.Insert(val)
+			return nil
+		}
+	}
+
+	// maxNd has no right child, so it is the last node on the search path of val
+	rb.attach(rb.maxNd, val)
+	return nil
+}
+
+// Finds where a new node with the given value would be attached, and returns its would-be parent.
+// Unless duplicates are kept, a node with an equal value is returned instead, if there is one.
+func (rb *RBTree[T]) locate(val T) (p, existing *node[T]) {
+	nd := rb.root
+
+	for nd != nil {
+		c := rb.cmp(val, nd.value)
+		if c == 0 && rb.dups != KeepDuplicates {
+			return nil, nd
+		}
+
+		p = nd
+		if rb.goesLeft(c) {
+			nd = nd.left
+		} else {
+			nd = nd.right
+		}
+	}
+
+	return p, nil
+}
+
+// Returns true if a new value comparing as c against a node belongs in the left subtree of the node.
+// Equal values go left, unless they are kept in insertion order, in which case they go after the existing ones.
+func (rb *RBTree[T]) goesLeft(c int) bool {
+	return c < 0 || (c == 0 && !rb.insertionOrder)
+}
+
+// Applies the duplicate policy when val is inserted while nd already has an equal value.
+func (rb *RBTree[T]) resolveDuplicate(nd *node[T], val T) {
+	if rb.dups != ReplaceDuplicates {
+		return
+	}
+
+	old := nd.value
+	nd.value = val
+	if rb.augment != nil {
+		// the new value replaces the augmented data that was kept in the old one
+		rb.updateUp(nd)
+	}
+	rb.notify(Deleted, old)
+	rb.notify(Inserted, val)
+}
+
+// Inserts a new node with the given value only if no node has an equal value, using a single descent.
+// Returns the node having the value, and whether it was newly inserted.
+func (rb *RBTree[T]) insertUnique(val T) (*node[T], bool) {
 	nd := rb.root
 	var p *node[T] = nil
 
 	for nd != nil {
+		c := rb.cmp(val, nd.value)
+		if c == 0 {
+			return nd, false
+		}
+
 		p = nd
-		if val <= nd.value {
+		if c < 0 {
 			nd = nd.left
 		} else {
 			nd = nd.right
 		}
 	}
 
+	return rb.attach(p, val), true
+}
+
+// Attaches a new node with the given value as a child of p, and returns the new node.
+// p must be the last node on the search path of val, or nil if the tree is empty.
+func (rb *RBTree[T]) attach(p *node[T], val T) *node[T] {
+	newNd := rb.link(p, val)
+
+	// At this point all properties of red-black trees are satisfied, except parent may be also be red.
+	rb.balancer().fixInsert(rb, newNd)
+	rb.debugValidate()
+	rb.notify(Inserted, val)
+	return newNd
+}
+
+// Links a new node with the given value as a child of p without fixing the colors, and returns the new node.
+// The new node is red, unless it becomes the root.
+// p must be the last node on the search path of val, or nil if the tree is empty.
+func (rb *RBTree[T]) link(p *node[T], val T) *node[T] {
 	rb.len++
+	rb.gen++
 	newNd := &node[T]{
 		value: val,
+		sz:    1,
+		ht:    1,
+	}
+	if rb.insertionOrder {
+		rb.seq++
+		newNd.seq = rb.seq
 	}
 
 	if p == nil {
 		newNd.clr = black
 		rb.root = newNd
-		return
+		rb.minNd, rb.maxNd = newNd, newNd
+		return newNd
 	}
 
 	newNd.clr = red
 	newNd.parent = p
 
-	if val <= p.value {
+	// a new leaf is the new minimum only if it goes left of the old one, and likewise for the maximum
+	if rb.goesLeft(rb.cmp(val, p.value)) {
 		p.left = newNd
+		if p == rb.minNd {
+			rb.minNd = newNd
+		}
 	} else {
 		p.right = newNd
+		if p == rb.maxNd {
+			rb.maxNd = newNd
+		}
 	}
 
-	// At this point all properties of red-black trees are satisfied, except parent may be also be red.
-	rb.fixInsert(newNd)
+	// new node is in the subtree of every ancestor
+	rb.updateUp(p)
+	return newNd
 }
 
 // Returns true if there exists a node having the given value in the tree.
@@ -141,33 +379,78 @@ func (rb *RBTree[T]) GetValues() []T {
 
 // Deletes a node in the tree with the given value.
 // If there are multiple such nodes, any one of them might be deleted.
-// Non-nill error is returned if no such node is found. Otherwise, nil is returned.
+// Returns an error wrapping ErrValueDoesNotExist if no such node is found. Otherwise, nil is returned.
 // Working is similar to deletion of node in a normal BST. Only addition is the fixing part.
-// TODO: Understand how the fuck fixing works.
 func (rb *RBTree[T]) Delete(val T) error {
 	nd := rb.findNode(val)
 	if nd == nil {
-		return ErrValueDoesNotExist
+		return fmt.Errorf("delete %v: %w", val, ErrValueDoesNotExist)
 	}
 
+	rb.deleteNode(nd)
+	return nil
+}
+
+// Deletes the first node in ascending order with the given value, making the choice among duplicates deterministic.
+// Returns an error wrapping ErrValueDoesNotExist if no such node is found.
+func (rb *RBTree[T]) DeleteFirst(val T) error {
+	nd := rb.ceilingNode(val)
+	if nd == nil || rb.cmp(nd.value, val) != 0 {
+		return fmt.Errorf("delete first %v: %w", val, ErrValueDoesNotExist)
+	}
+
+	rb.deleteNode(nd)
+	return nil
+}
+
+// Deletes the last node in ascending order with the given value, making the choice among duplicates deterministic.
+// Returns an error wrapping ErrValueDoesNotExist if no such node is found.
+func (rb *RBTree[T]) DeleteLast(val T) error {
+	nd := rb.floorNode(val)
+	if nd == nil || rb.cmp(nd.value, val) != 0 {
+		return fmt.Errorf("delete last %v: %w", val, ErrValueDoesNotExist)
+	}
+
+	rb.deleteNode(nd)
+	return nil
+}
+
+// Removes the given non-nil node from the tree.
+func (rb *RBTree[T]) deleteNode(nd *node[T]) {
 	rb.len--
+	rb.gen++
+
+	// nodes are relinked rather than moving values between them, so the neighbors stay valid after removal
+	if nd == rb.minNd {
+		rb.minNd = nd.successor()
+	}
+	if nd == rb.maxNd {
+		rb.maxNd = nd.predecessor()
+	}
 
 	ogColor := nd.clr
 	var ndToFix *node[T] = nil
+	// parent of ndToFix after removal, tracked separately since ndToFix can be nil
+	var fixParent *node[T] = nil
 
 	if nd.left == nil {
 		ndToFix = nd.right
+		fixParent = nd.parent
 		rb.replace(nd, ndToFix)
 	} else if nd.right == nil {
 		ndToFix = nd.left
+		fixParent = nd.parent
 		rb.replace(nd, ndToFix)
 	} else {
 		// substitute for nd
 		sub := nd.right.getMin()
 		ogColor = sub.clr
 		ndToFix = sub.right
+		fixParent = sub
 
 		if sub.parent != nd {
+			fixParent = sub.parent
+
 			// first replace substitute by its right child
 			// this is easy since sub.left == nil
 			rb.replace(sub, sub.right)
@@ -185,11 +468,13 @@ func (rb *RBTree[T]) Delete(val T) error {
 		sub.clr = nd.clr
 	}
 
-	if ogColor == black {
-		rb.fixDelete(ndToFix)
-	}
+	// fixParent is the lowest node whose subtree lost a node
+	rb.updateUp(fixParent)
 
-	return nil
+	rb.balancer().fixDelete(rb, ndToFix, fixParent, ogColor == black)
+
+	rb.debugValidate()
+	rb.notify(Deleted, nd.value)
 }
 
 // Returns non-nil pointer to the first node found with the given value.
@@ -197,9 +482,10 @@ func (rb *RBTree[T]) findNode(val T) *node[T] {
 	nd := rb.root
 
 	for nd != nil {
-		if nd.value == val {
+		c := rb.cmp(nd.value, val)
+		if c == 0 {
 			return nd
-		} else if nd.value < val {
+		} else if c < 0 {
 			nd = nd.right
 		} else {
 			nd = nd.left
@@ -217,6 +503,137 @@ func (nd *node[T]) getMin() *node[T] {
 	return nd
 }
 
+// Find the node with maximum value in subtree rooted at nd.
+func (nd *node[T]) getMax() *node[T] {
+	for nd.right != nil {
+		nd = nd.right
+	}
+	return nd
+}
+
+// Returns the node after nd in inorder traversal, or nil if nd is the last node.
+func (nd *node[T]) successor() *node[T] {
+	if nd.right != nil {
+		return nd.right.getMin()
+	}
+
+	// climb up until we arrive from a left subtree
+	p := nd.parent
+	for p != nil && nd == p.right {
+		nd = p
+		p = p.parent
+	}
+	return p
+}
+
+// Returns the node before nd in inorder traversal, or nil if nd is the first node.
+func (nd *node[T]) predecessor() *node[T] {
+	if nd.left != nil {
+		return nd.left.getMax()
+	}
+
+	// climb up until we arrive from a right subtree
+	p := nd.parent
+	for p != nil && nd == p.left {
+		nd = p
+		p = p.parent
+	}
+	return p
+}
+
+// Returns the first node in inorder traversal, or nil if the tree is empty. Takes O(1) time.
+func (rb *RBTree[T]) minNode() *node[T] {
+	return rb.minNd
+}
+
+// Returns the last node in inorder traversal, or nil if the tree is empty. Takes O(1) time.
+func (rb *RBTree[T]) maxNode() *node[T] {
+	return rb.maxNd
+}
+
+// Replaces all nodes of the tree with the tree rooted at root having n nodes, which may be nil,
+// and finds its first and last nodes. This counts as a structural modification.
+func (rb *RBTree[T]) setRoot(root *node[T], n int) {
+	rb.root, rb.len = root, n
+	rb.gen++
+
+	if root == nil {
+		rb.minNd, rb.maxNd = nil, nil
+	} else {
+		rb.minNd, rb.maxNd = root.getMin(), root.getMax()
+	}
+}
+
+// Returns the first node in inorder traversal with value >= val, or nil if there is none.
+func (rb *RBTree[T]) ceilingNode(val T) *node[T] {
+	nd := rb.root
+	var res *node[T] = nil
+
+	for nd != nil {
+		if rb.cmp(nd.value, val) >= 0 {
+			// nd is a candidate, but there might be a smaller one on the left
+			res = nd
+			nd = nd.left
+		} else {
+			nd = nd.right
+		}
+	}
+
+	return res
+}
+
+// Returns the first node in inorder traversal with value > val, or nil if there is none.
+func (rb *RBTree[T]) higherNode(val T) *node[T] {
+	nd := rb.root
+	var res *node[T] = nil
+
+	for nd != nil {
+		if rb.cmp(nd.value, val) > 0 {
+			res = nd
+			nd = nd.left
+		} else {
+			nd = nd.right
+		}
+	}
+
+	return res
+}
+
+// Returns the last node in inorder traversal with value < val, or nil if there is none.
+func (rb *RBTree[T]) lowerNode(val T) *node[T] {
+	nd := rb.root
+	var res *node[T] = nil
+
+	for nd != nil {
+		if rb.cmp(nd.value, val) < 0 {
+			res = nd
+			nd = nd.right
+		} else {
+			nd = nd.left
+		}
+	}
+
+	return res
+}
+
+// Returns the last node in inorder traversal with value <= val, or nil if there is none.
+func (rb *RBTree[T]) floorNode(val T) *node[T] {
+	nd := rb.root
+	var res *node[T] = nil
+
+	for nd != nil {
+		if rb.cmp(nd.value, val) <= 0 {
+			// nd is a candidate, but there might be a larger one on the right
+			res = nd
+			nd = nd.right
+		} else {
+			nd = nd.left
+		}
+	}
+
+	return res
+}
+
 // Replace a node with its substitute in the tree without affecting their children.
 // Substitute can be nil, but not the node.
 func (rb *RBTree[T]) replace(nd, sub *node[T]) {
@@ -242,6 +659,7 @@ func (rb *RBTree[T]) rotateLeft(nd *node[T]) {
 		return
 	}
 
+	rb.rotations++
 	r := nd.right
 	rb.replace(nd, r)
 
@@ -251,6 +669,11 @@ func (rb *RBTree[T]) rotateLeft(nd *node[T]) {
 		nd.right.parent = nd
 	}
 	r.left = nd
+
+	// only nd and r have new subtrees. The sizes of ancestors don't change, but their heights might,
+	// so the balancer updates them once it is done rotating.
+	rb.update(nd)
+	rb.update(r)
 }
 
 // Right rotates the the node to balance the tree.
@@ -260,6 +683,7 @@ func (rb *RBTree[T]) rotateRight(nd *node[T]) {
 		return
 	}
 
+	rb.rotations++
 	l := nd.left
 	rb.replace(nd, l)
 
@@ -269,6 +693,11 @@ func (rb *RBTree[T]) rotateRight(nd *node[T]) {
 		nd.left.parent = nd
 	}
 	l.right = nd
+
+	// only nd and l have new subtrees. The sizes of ancestors don't change, but their heights might,
+	// so the balancer updates them once it is done rotating.
+	rb.update(nd)
+	rb.update(l)
 }
 
 // Newly inserted non-root nodes are red by default.
@@ -337,81 +766,86 @@ func (rb *RBTree[T]) fixInsert(nd *node[T]) {
 		}
 	}
 
-	// If nd is not nil, root is non-nill.
-	rb.root.clr = black
+	rb.root.setColor(black)
 }
 
-// Copied brainlessly from CLRS.
-// TODO: Understand how it works.
-func (rb *RBTree[T]) fixDelete(nd *node[T]) {
+// Removing a black node leaves nd with an extra black, making it "doubly black".
+// This pushes the extra black up the tree until it can be absorbed by a red node or the root.
+// Adapted from CLRS, with nil playing the role of the sentinel leaf.
+// Since nil has no parent field to write to, the parent p of nd is passed explicitly (p is nil only when nd is the root).
+func (rb *RBTree[T]) fixDelete(nd, p *node[T]) {
 	for nd != rb.root && nd.color() == black {
-		// parent is non-nill since nd != root
-		if nd == nd.parent.left {
-			sib := nd.parent.right
+		// p is non-nil since nd != root
+		if nd == p.left {
+			// nd carries an extra black, so its sibling subtree has black height >= 1.
+			// This means sib is non-nil in a valid tree, but the accessors below are nil-safe regardless.
+			sib := p.right
 
 			if sib.color() == red {
 				// sib is non-nill since color is red
 				sib.clr = black
-				nd.parent.clr = red
-				rb.rotateLeft(nd.parent)
+				p.setColor(red)
+				rb.rotateLeft(p)
 				// sib will change after rotation
-				sib = nd.parent.right
+				sib = p.right
 			}
 
-			// what if sib == nil?? CLRS doesn't cover this so I will pretend to be blind.
-
-			if sib.left.color() == black && sib.right.color() == black {
-				sib.clr = red
-				nd = nd.parent
+			if sib.getLeft().color() == black && sib.getRight().color() == black {
+				sib.setColor(red)
+				nd = p
+				p = nd.parent
 			} else {
-				if sib.right.color() == black {
-					sib.left.clr = black
-					sib.clr = red
+				if sib.getRight().color() == black {
+					sib.getLeft().setColor(black)
+					sib.setColor(red)
 					rb.rotateRight(sib)
 					// sib will change after rotation
-					sib = nd.parent.right
+					sib = p.right
 				}
 
-				sib.clr = nd.parent.clr
-				nd.parent.clr = black
-				sib.right.clr = black
-				rb.rotateLeft(nd.parent)
+				sib.setColor(p.clr)
+				p.clr = black
+				sib.getRight().setColor(black)
+				rb.rotateLeft(p)
 				nd = rb.root
+				p = nil
 			}
 		} else {
-			sib := nd.parent.left
+			// nd carries an extra black, so its sibling subtree has black height >= 1.
+			// This means sib is non-nil in a valid tree, but the accessors below are nil-safe regardless.
+			sib := p.left
 
 			if sib.color() == red {
 				// sib is non-nill since color is red
 				sib.clr = black
-				nd.parent.clr = red
-				rb.rotateRight(nd.parent)
+				p.setColor(red)
+				rb.rotateRight(p)
 				// sib will change after rotation
-				sib = nd.parent.left
+				sib = p.left
 			}
 
-			// what if sib == nil?? CLRS doesn't cover this so I will pretend to be blind.
-
-			if sib.left.color() == black && sib.right.color() == black {
-				sib.clr = red
-				nd = nd.parent
+			if sib.getLeft().color() == black && sib.getRight().color() == black {
+				sib.setColor(red)
+				nd = p
+				p = nd.parent
 			} else {
-				if sib.left.color() == black {
-					sib.right.clr = black
-					sib.clr = red
-					rb.rotateRight(sib)
+				if sib.getLeft().color() == black {
+					sib.getRight().setColor(black)
+					sib.setColor(red)
+					rb.rotateLeft(sib)
 					// sib will change after rotation
-					sib = nd.parent.left
+					sib = p.left
 				}
 
-				sib.clr = nd.parent.clr
-				nd.parent.clr = black
-				sib.left.clr = black
-				rb.rotateRight(nd.parent)
+				sib.setColor(p.clr)
+				p.clr = black
+				sib.getLeft().setColor(black)
+				rb.rotateRight(p)
 				nd = rb.root
+				p = nil
 			}
 		}
 	}
 
-	rb.root.clr = black
+	nd.setColor(black)
 }
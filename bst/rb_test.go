@@ -0,0 +1,281 @@
+package bst
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// Fails the test if the tree violates any of its invariants.
+func checkValid[T any](t *testing.T, rb *RBTree[T]) {
+	t.Helper()
+	if err := rb.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Fails the test if the tree is invalid or doesn't hold exactly want, in ascending order.
+func checkValues[T comparable](t *testing.T, rb *RBTree[T], want []T) {
+	t.Helper()
+	checkValid(t, rb)
+	if got := rb.GetValues(); !slices.Equal(got, want) {
+		t.Fatalf("got values %v, want %v", got, want)
+	}
+	if rb.Len() != len(want) {
+		t.Fatalf("got Len %d, want %d", rb.Len(), len(want))
+	}
+}
+
+// The tree configurations that every randomized test runs against.
+var treeConfigs = []struct {
+	name string
+	opts []Option
+}{
+	{"red-black", nil},
+	{"avl", []Option{WithBalancing(AVL)}},
+	{"insertion-order", []Option{WithInsertionOrder()}},
+}
+
+func TestInsertDeleteRandom(t *testing.T) {
+	for _, cfg := range treeConfigs {
+		t.Run(cfg.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			rb := NewRBTree[int](cfg.opts...)
+			var want []int
+
+			for i := 0; i < 3000; i++ {
+				v := r.Intn(200)
+				if r.Intn(3) > 0 {
+					rb.Insert(v)
+					want = append(want, v)
+					slices.Sort(want)
+				} else {
+					idx := slices.Index(want, v)
+					err := rb.Delete(v)
+					if idx < 0 {
+						if !errors.Is(err, ErrValueDoesNotExist) {
+							t.Fatalf("deleting missing %d: got error %v", v, err)
+						}
+						continue
+					}
+					if err != nil {
+						t.Fatalf("deleting %d: %v", v, err)
+					}
+					want = slices.Delete(want, idx, idx+1)
+				}
+				checkValid(t, rb)
+			}
+
+			checkValues(t, rb, want)
+		})
+	}
+}
+
+func TestDeleteToEmptyAndReinsert(t *testing.T) {
+	for _, cfg := range treeConfigs {
+		t.Run(cfg.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(2))
+			rb := NewRBTree[int](cfg.opts...)
+
+			for round := 0; round < 5; round++ {
+				vals := r.Perm(100)
+				for _, v := range vals {
+					rb.Insert(v)
+					checkValid(t, rb)
+				}
+
+				r.Shuffle(len(vals), func(i, j int) { vals[i], vals[j] = vals[j], vals[i] })
+				for _, v := range vals {
+					if err := rb.Delete(v); err != nil {
+						t.Fatal(err)
+					}
+					checkValid(t, rb)
+				}
+
+				checkValues(t, rb, nil)
+				if rb.Height() != 0 {
+					t.Fatalf("empty tree has height %d", rb.Height())
+				}
+				if _, ok := rb.Min(); ok {
+					t.Fatal("empty tree has a minimum")
+				}
+				if _, err := rb.PopMax(); !errors.Is(err, ErrEmptyTree) {
+					t.Fatalf("PopMax on empty tree: got error %v", err)
+				}
+				if rb.DeleteMin() || rb.DeleteMax() {
+					t.Fatal("deleted from an empty tree")
+				}
+			}
+
+			rb.Insert(5)
+			checkValues(t, rb, []int{5})
+		})
+	}
+}
+
+func TestDuplicatePolicy(t *testing.T) {
+	type record struct {
+		key  int
+		name string
+	}
+	byKey := func(a, b record) int { return a.key - b.key }
+
+	keep := NewRBTreeFunc(byKey)
+	reject := NewRBTreeFunc(byKey, WithDuplicatePolicy(RejectDuplicates))
+	replace := NewRBTreeFunc(byKey, WithDuplicatePolicy(ReplaceDuplicates))
+
+	for _, rb := range []*RBTree[record]{keep, reject, replace} {
+		rb.Insert(record{1, "a"})
+		rb.Insert(record{2, "b"})
+		rb.Insert(record{1, "c"})
+		checkValid(t, rb)
+	}
+
+	if keep.Len() != 3 {
+		t.Errorf("keep: got Len %d, want 3", keep.Len())
+	}
+	if got, _ := reject.Min(); reject.Len() != 2 || got.name != "a" {
+		t.Errorf("reject: got Len %d and min %v", reject.Len(), got)
+	}
+	if got, _ := replace.Min(); replace.Len() != 2 || got.name != "c" {
+		t.Errorf("replace: got Len %d and min %v", replace.Len(), got)
+	}
+}
+
+func TestPointerElements(t *testing.T) {
+	type record struct {
+		key  int
+		name string
+	}
+	rb := NewRBTreeFunc(func(a, b *record) int { return a.key - b.key })
+
+	stored := &record{1, "stored"}
+	rb.Insert(stored)
+	rb.Insert(&record{2, "other"})
+
+	// a different pointer to a different record with an equal key
+	probe := &record{1, "probe"}
+	if !rb.Exists(probe) {
+		t.Fatal("Exists compared pointers instead of keys")
+	}
+	if rb.Exists(&record{3, "stored"}) {
+		t.Fatal("Exists matched a record with a different key")
+	}
+
+	if err := rb.Delete(probe); err != nil {
+		t.Fatalf("Delete compared pointers instead of keys: %v", err)
+	}
+	checkValid(t, rb)
+	if rb.Exists(stored) || rb.Len() != 1 {
+		t.Fatalf("Delete left the stored record, Len %d", rb.Len())
+	}
+	if err := rb.Delete(probe); !errors.Is(err, ErrValueDoesNotExist) {
+		t.Fatalf("deleting a missing key: got error %v", err)
+	}
+}
+
+func TestInsertionOrder(t *testing.T) {
+	type record struct {
+		key, seq int
+	}
+	rb := NewRBTreeFunc(func(a, b record) int { return a.key - b.key }, WithInsertionOrder())
+
+	for i := 0; i < 50; i++ {
+		rb.Insert(record{i % 3, i})
+	}
+	checkValid(t, rb)
+
+	prev := record{-1, -1}
+	for v, seq := range rb.AllWithSeq() {
+		if v.key == prev.key && v.seq < prev.seq {
+			t.Fatalf("%v comes after %v despite being inserted earlier", v, prev)
+		}
+		if uint64(v.seq+1) != seq {
+			t.Fatalf("%v has sequence number %d", v, seq)
+		}
+		prev = v
+	}
+}
+
+func TestDeleteFirstLast(t *testing.T) {
+	type record struct {
+		key, id int
+	}
+	rb := NewRBTreeFunc(func(a, b record) int { return a.key - b.key }, WithInsertionOrder())
+	for i := 0; i < 30; i++ {
+		rb.Insert(record{i % 3, i})
+	}
+
+	// equal records are kept in insertion order, so the first one with key 1 has id 1, and the last has id 28
+	if err := rb.DeleteFirst(record{1, -1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rb.DeleteLast(record{1, -1}); err != nil {
+		t.Fatal(err)
+	}
+	checkValid(t, rb)
+
+	var got, want []int
+	for v := range rb.All() {
+		if v.key == 1 {
+			got = append(got, v.id)
+		}
+	}
+	for id := 4; id < 28; id += 3 {
+		want = append(want, id)
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got ids %v, want %v", got, want)
+	}
+
+	if err := rb.DeleteFirst(record{5, 0}); !errors.Is(err, ErrValueDoesNotExist) {
+		t.Fatalf("DeleteFirst of a missing value: got error %v", err)
+	}
+	if err := rb.DeleteLast(record{5, 0}); !errors.Is(err, ErrValueDoesNotExist) {
+		t.Fatalf("DeleteLast of a missing value: got error %v", err)
+	}
+	if rb.Len() != 28 {
+		t.Fatalf("got Len %d, want 28", rb.Len())
+	}
+}
+
+func TestAppend(t *testing.T) {
+	for _, cfg := range treeConfigs {
+		t.Run(cfg.name, func(t *testing.T) {
+			rb := NewRBTree[int](cfg.opts...)
+			var want []int
+
+			for i := 0; i < 500; i++ {
+				if err := rb.Append(i / 3); err != nil {
+					t.Fatal(err)
+				}
+				want = append(want, i/3)
+				checkValid(t, rb)
+			}
+			checkValues(t, rb, want)
+
+			if err := rb.Append(3); !errors.Is(err, ErrOutOfOrder) {
+				t.Fatalf("appending a smaller value: got error %v", err)
+			}
+			checkValues(t, rb, want)
+		})
+	}
+}
+
+func TestOnChange(t *testing.T) {
+	rb := NewRBTree[int]()
+	var events []ChangeEvent[int]
+	rb.OnChange(func(e ChangeEvent[int]) {
+		events = append(events, e)
+	})
+
+	rb.InsertAll(3, 1, 2)
+	_ = rb.Delete(1)
+	_ = rb.Delete(7)
+
+	want := []ChangeEvent[int]{{Inserted, 3}, {Inserted, 1}, {Inserted, 2}, {Deleted, 1}}
+	if !slices.Equal(events, want) {
+		t.Fatalf("got events %v, want %v", events, want)
+	}
+}
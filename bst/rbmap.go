@@ -0,0 +1,193 @@
+package bst
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"slices"
+)
+
+type mapEntry[K, V any] struct {
+	key K
+	val V
+}
+
+// RBMap is an ordered map backed by RBTree.
+// Each key is stored at most once, and entries are ordered by key.
+type RBMap[K, V any] struct {
+	tree *RBTree[mapEntry[K, V]]
+}
+
+func NewRBMap[K cmp.Ordered, V any]() *RBMap[K, V] {
+	return &RBMap[K, V]{
+		tree: NewRBTreeFunc(compareKeys[K, V]),
+	}
+}
+
+// Builds a map from the given key-value pairs in O(n log n) time for sorting, and O(n) for building the tree.
+// If a key appears multiple times, the value of the last such pair wins.
+// The input slice is not modified.
+func NewRBMapFromPairs[K cmp.Ordered, V any](pairs []struct {
+	Key K
+	Val V
+}) *RBMap[K, V] {
+	entries := make([]mapEntry[K, V], len(pairs))
+	for i, p := range pairs {
+		entries[i] = mapEntry[K, V]{key: p.Key, val: p.Val}
+	}
+
+	// stable sort keeps pairs with the same key in input order, so the last one is the latest write
+	slices.SortStableFunc(entries, compareKeys[K, V])
+
+	unique := entries[:0]
+	for _, e := range entries {
+		if len(unique) > 0 && cmp.Compare(unique[len(unique)-1].key, e.key) == 0 {
+			unique[len(unique)-1] = e
+		} else {
+			unique = append(unique, e)
+		}
+	}
+
+	m := NewRBMap[K, V]()
+	m.tree.setRoot(buildBalanced(unique), len(unique))
+	return m
+}
+
+// Builds an ordered map with the same entries as the given map in O(n log n) time.
+// The source map is not modified.
+func NewRBTreeFromMap[K cmp.Ordered, V any](m map[K]V) *RBMap[K, V] {
+	entries := make([]mapEntry[K, V], 0, len(m))
+	for k, v := range m {
+		entries = append(entries, mapEntry[K, V]{key: k, val: v})
+	}
+
+	// keys of a map are unique, so no need to deduplicate
+	slices.SortFunc(entries, compareKeys[K, V])
+
+	res := NewRBMap[K, V]()
+	res.tree.setRoot(buildBalanced(entries), len(entries))
+	return res
+}
+
+func compareKeys[K cmp.Ordered, V any](a, b mapEntry[K, V]) int {
+	return cmp.Compare(a.key, b.key)
+}
+
+// Returns the number of entries in the map.
+func (m *RBMap[K, V]) Len() int {
+	return m.tree.Len()
+}
+
+// Sets the value for the given key, overwriting the existing value if any.
+func (m *RBMap[K, V]) Put(k K, v V) {
+	if nd, inserted := m.tree.insertUnique(mapEntry[K, V]{key: k, val: v}); !inserted {
+		nd.value.val = v
+	}
+}
+
+// Returns the value for the given key, and whether the key exists in the map.
+func (m *RBMap[K, V]) Get(k K) (V, bool) {
+	nd := m.tree.findNode(mapEntry[K, V]{key: k})
+	if nd == nil {
+		var zero V
+		return zero, false
+	}
+	return nd.value.val, true
+}
+
+// Returns true if the key exists in the map.
+func (m *RBMap[K, V]) Has(k K) bool {
+	return m.tree.findNode(mapEntry[K, V]{key: k}) != nil
+}
+
+// Deletes the entry with the given key.
+// Returns an error wrapping ErrKeyDoesNotExist if there is no such entry.
+func (m *RBMap[K, V]) Delete(k K) error {
+	nd := m.tree.findNode(mapEntry[K, V]{key: k})
+	if nd == nil {
+		return fmt.Errorf("delete %v: %w", k, ErrKeyDoesNotExist)
+	}
+	m.tree.deleteNode(nd)
+	return nil
+}
+
+// Returns the keys in ascending order.
+func (m *RBMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.Len())
+	for nd := m.tree.minNode(); nd != nil; nd = nd.successor() {
+		keys = append(keys, nd.value.key)
+	}
+	return keys
+}
+
+// Returns an iterator over the entries in ascending order of keys.
+func (m *RBMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := range m.tree.All() {
+			if !yield(e.key, e.val) {
+				return
+			}
+		}
+	}
+}
+
+// Returns an iterator over the entries in descending order of keys.
+func (m *RBMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := range m.tree.Backward() {
+			if !yield(e.key, e.val) {
+				return
+			}
+		}
+	}
+}
+
+// Returns the entry with the greatest key <= k, or false if there is no such entry.
+func (m *RBMap[K, V]) FloorEntry(k K) (K, V, bool) {
+	return entryOf(m.tree.floorNode(mapEntry[K, V]{key: k}))
+}
+
+// Returns the entry with the least key >= k, or false if there is no such entry.
+func (m *RBMap[K, V]) CeilingEntry(k K) (K, V, bool) {
+	return entryOf(m.tree.ceilingNode(mapEntry[K, V]{key: k}))
+}
+
+// Returns the first entry with key >= k, like lower_bound of C++ std::map. Same as CeilingEntry.
+// Returns false if there is no such entry.
+func (m *RBMap[K, V]) LowerBoundEntry(k K) (K, V, bool) {
+	return entryOf(m.tree.ceilingNode(mapEntry[K, V]{key: k}))
+}
+
+// Returns the first entry with key > k, like upper_bound of C++ std::map.
+// Returns false if there is no such entry.
+func (m *RBMap[K, V]) UpperBoundEntry(k K) (K, V, bool) {
+	return entryOf(m.tree.higherNode(mapEntry[K, V]{key: k}))
+}
+
+// Unpacks the entry stored in nd, which may be nil.
+func entryOf[K, V any](nd *node[mapEntry[K, V]]) (K, V, bool) {
+	if nd == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	return nd.value.key, nd.value.val, true
+}
+
+// Returns the existing value for the given key if present, with loaded set to true.
+// Otherwise, it stores v and returns it, with loaded set to false.
+// Takes a single descent down the tree.
+func (m *RBMap[K, V]) GetOrPut(k K, v V) (actual V, loaded bool) {
+	nd, inserted := m.tree.insertUnique(mapEntry[K, V]{key: k, val: v})
+	return nd.value.val, !inserted
+}
+
+// Returns the entry with the smallest key, or false if the map is empty.
+func (m *RBMap[K, V]) MinEntry() (K, V, bool) {
+	return entryOf(m.tree.minNode())
+}
+
+// Returns the entry with the largest key, or false if the map is empty.
+func (m *RBMap[K, V]) MaxEntry() (K, V, bool) {
+	return entryOf(m.tree.maxNode())
+}
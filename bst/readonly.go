@@ -0,0 +1,50 @@
+package bst
+
+import "iter"
+
+// ReadOnlyTree exposes only the queries of an RBTree, for passing a tree to code that shouldn't modify it.
+// All its methods are non-mutating, so concurrent readers are safe as long as nobody writes to the tree.
+type ReadOnlyTree[T any] interface {
+	Len() int
+	Exists(val T) bool
+	Min() (T, bool)
+	Max() (T, bool)
+	Floor(val T) (T, bool)
+	Ceiling(val T) (T, bool)
+	Successor(val T) (T, bool)
+	Predecessor(val T) (T, bool)
+	Select(k int) (T, error)
+	IndexOf(val T) (int, bool)
+	Range(lo, hi T) []T
+	All() iter.Seq[T]
+	Backward() iter.Seq[T]
+	From(start T) iter.Seq[T]
+	DownFrom(start T) iter.Seq[T]
+}
+
+// Wraps the tree so that callers can't type assert their way back to the mutators.
+type readOnlyTree[T any] struct {
+	tree *RBTree[T]
+}
+
+// Returns a read-only view of the tree. Later changes to the tree are visible through the view.
+// GetValues is deliberately left out, since Morris traversal temporarily modifies the tree.
+func (rb *RBTree[T]) ReadOnly() ReadOnlyTree[T] {
+	return readOnlyTree[T]{tree: rb}
+}
+
+func (ro readOnlyTree[T]) Len() int                     { return ro.tree.Len() }
+func (ro readOnlyTree[T]) Exists(val T) bool            { return ro.tree.Exists(val) }
+func (ro readOnlyTree[T]) Min() (T, bool)               { return ro.tree.Min() }
+func (ro readOnlyTree[T]) Max() (T, bool)               { return ro.tree.Max() }
+func (ro readOnlyTree[T]) Floor(val T) (T, bool)        { return ro.tree.Floor(val) }
+func (ro readOnlyTree[T]) Ceiling(val T) (T, bool)      { return ro.tree.Ceiling(val) }
+func (ro readOnlyTree[T]) Successor(val T) (T, bool)    { return ro.tree.Successor(val) }
+func (ro readOnlyTree[T]) Predecessor(val T) (T, bool)  { return ro.tree.Predecessor(val) }
+func (ro readOnlyTree[T]) Select(k int) (T, error)      { return ro.tree.Select(k) }
+func (ro readOnlyTree[T]) IndexOf(val T) (int, bool)    { return ro.tree.IndexOf(val) }
+func (ro readOnlyTree[T]) Range(lo, hi T) []T           { return ro.tree.Range(lo, hi) }
+func (ro readOnlyTree[T]) All() iter.Seq[T]             { return ro.tree.All() }
+func (ro readOnlyTree[T]) Backward() iter.Seq[T]        { return ro.tree.Backward() }
+func (ro readOnlyTree[T]) From(start T) iter.Seq[T]     { return ro.tree.From(start) }
+func (ro readOnlyTree[T]) DownFrom(start T) iter.Seq[T] { return ro.tree.DownFrom(start) }
@@ -0,0 +1,36 @@
+package bst
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{1, 3, 5})
+	view := rb.ReadOnly()
+
+	rb.Insert(4)
+	if err := rb.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := slices.Collect(view.All()), []int{3, 4, 5}; !slices.Equal(got, want) || view.Len() != 3 {
+		t.Fatalf("the view doesn't show later writes: got %v, want %v", got, want)
+	}
+	if lo, _ := view.Min(); lo != 3 || !view.Exists(4) || view.Exists(1) {
+		t.Fatalf("the view doesn't show later writes: got Min %d", lo)
+	}
+
+	if _, ok := view.(*RBTree[int]); ok {
+		t.Fatal("the view can be type asserted back to the tree")
+	}
+	// the view's dynamic type must have no methods beyond the interface, so no type assertion reaches a mutator
+	iface := reflect.TypeFor[ReadOnlyTree[int]]()
+	typ := reflect.TypeOf(view)
+	for i := 0; i < typ.NumMethod(); i++ {
+		name := typ.Method(i).Name
+		if _, ok := iface.MethodByName(name); !ok {
+			t.Errorf("the view exposes %s", name)
+		}
+	}
+}
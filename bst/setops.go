@@ -0,0 +1,90 @@
+package bst
+
+import "slices"
+
+// Compares the tree with other and returns the values that would need to be added to and removed from
+// this tree to make it equal to other. Both slices are in ascending order.
+// Duplicates are matched one to one, so if this tree has a value 3 times and other has it once,
+// removed will contain the value twice.
+// Takes O(n + m) time using a single merge walk over both trees.
+func (rb *RBTree[T]) Diff(other *RBTree[T]) (added, removed []T) {
+	a, b := rb.minNode(), other.minNode()
+
+	for a != nil && b != nil {
+		c := rb.cmp(a.value, b.value)
+		if c < 0 {
+			removed = append(removed, a.value)
+			a = a.successor()
+		} else if c > 0 {
+			added = append(added, b.value)
+			b = b.successor()
+		} else {
+			a = a.successor()
+			b = b.successor()
+		}
+	}
+
+	for ; a != nil; a = a.successor() {
+		removed = append(removed, a.value)
+	}
+
+	for ; b != nil; b = b.successor() {
+		added = append(added, b.value)
+	}
+
+	return added, removed
+}
+
+// Returns true if the values of the tree in ascending order match sorted element for element.
+// sorted is expected to be in ascending order, otherwise the result is simply false.
+// Stops at the first mismatch without allocating.
+func (rb *RBTree[T]) EqualsSlice(sorted []T) bool {
+	if len(sorted) != rb.len {
+		return false
+	}
+
+	i := 0
+	for nd := rb.minNode(); nd != nil; nd = nd.successor() {
+		if rb.cmp(nd.value, sorted[i]) != 0 {
+			return false
+		}
+		i++
+	}
+
+	return true
+}
+
+// Returns the values of sorted that exist in the tree, in ascending order.
+// sorted must be in ascending order. Every copy of a duplicated query value is returned if the value exists,
+// no matter how many times it is in the tree.
+// Takes O(n + m) time using a single merge walk over the tree and sorted, which beats calling Exists
+// for every value when m is large. If sorted is not in ascending order, each value is looked up separately,
+// and the values found are returned in their original order.
+func (rb *RBTree[T]) IntersectSlice(sorted []T) []T {
+	var found []T
+
+	if !slices.IsSortedFunc(sorted, rb.cmp) {
+		for _, v := range sorted {
+			if rb.findNode(v) != nil {
+				found = append(found, v)
+			}
+		}
+		return found
+	}
+
+	nd := rb.minNode()
+	for _, v := range sorted {
+		for nd != nil && rb.cmp(nd.value, v) < 0 {
+			nd = nd.successor()
+		}
+		if nd == nil {
+			break
+		}
+		// nd stays put, so duplicates in sorted match it too
+		if rb.cmp(nd.value, v) == 0 {
+			found = append(found, v)
+		}
+	}
+
+	return found
+}
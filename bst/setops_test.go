@@ -0,0 +1,43 @@
+package bst
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := NewRBTree[int]()
+	a.InsertAll(1, 2, 2, 2, 5, 7)
+	b := NewRBTree[int]()
+	b.InsertAll(2, 3, 5, 8, 8)
+
+	added, removed := a.Diff(b)
+	if want := []int{3, 8, 8}; !slices.Equal(added, want) {
+		t.Errorf("added: got %v, want %v", added, want)
+	}
+	if want := []int{1, 2, 2, 7}; !slices.Equal(removed, want) {
+		t.Errorf("removed: got %v, want %v", removed, want)
+	}
+}
+
+func TestEqualsSlice(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{3, 1, 2})
+
+	if !rb.EqualsSlice([]int{1, 2, 3}) {
+		t.Error("equal values reported as different")
+	}
+	if rb.EqualsSlice([]int{1, 2}) || rb.EqualsSlice([]int{1, 2, 4}) || rb.EqualsSlice([]int{3, 2, 1}) {
+		t.Error("different values reported as equal")
+	}
+}
+
+func TestIntersectSlice(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{1, 3, 5, 7})
+
+	if got, want := rb.IntersectSlice([]int{0, 3, 3, 4, 7, 9}), []int{3, 3, 7}; !slices.Equal(got, want) {
+		t.Errorf("sorted: got %v, want %v", got, want)
+	}
+	if got, want := rb.IntersectSlice([]int{7, 2, 1}), []int{7, 1}; !slices.Equal(got, want) {
+		t.Errorf("unsorted: got %v, want %v", got, want)
+	}
+}
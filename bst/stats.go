@@ -0,0 +1,123 @@
+package bst
+
+import "slices"
+
+// Same as Delete, but also returns the number of rotations performed while fixing the tree.
+// Both left and right rotations are counted.
+func (rb *RBTree[T]) DeleteWithStats(val T) (rotations int, err error) {
+	before := rb.rotations
+	err = rb.Delete(val)
+	return rb.rotations - before, err
+}
+
+// Returns the number of distinct values in the tree. Takes O(n) time.
+func (rb *RBTree[T]) DistinctCount() int {
+	count := 0
+	var prev *node[T] = nil
+
+	for nd := rb.minNode(); nd != nil; nd = nd.successor() {
+		// equal values are adjacent in inorder traversal
+		if prev == nil || rb.cmp(prev.value, nd.value) != 0 {
+			count++
+		}
+		prev = nd
+	}
+
+	return count
+}
+
+// Returns the number of nodes holding a value that already exists in an earlier node.
+// In other words, Len() - DistinctCount(). Takes O(n) time.
+func (rb *RBTree[T]) DuplicateCount() int {
+	return rb.len - rb.DistinctCount()
+}
+
+// Returns the number of values for which pred returns true.
+// Always walks the whole tree once in order, even if pred is monotonic over the sorted values. Takes O(n) time.
+func (rb *RBTree[T]) CountFunc(pred func(T) bool) int {
+	count := 0
+	for nd := rb.minNode(); nd != nil; nd = nd.successor() {
+		if pred(nd.value) {
+			count++
+		}
+	}
+	return count
+}
+
+// Returns true if inserting val would make the tree perform at least one rotation to rebalance itself.
+// The tree is not modified. This replays the decisions of the balancing strategy without making any changes.
+// Returns false if the duplicate policy means that val would not get a new node. Takes O(log n) time.
+func (rb *RBTree[T]) WouldRebalance(val T) bool {
+	p, existing := rb.locate(val)
+	if existing != nil {
+		return false
+	}
+	return rb.balancer().wouldRotate(p)
+}
+
+// Same as Exists, but also returns the number of value comparisons made while searching,
+// which is at most the height of the tree. Each node visited costs one comparison.
+// Exists itself is not instrumented, so it doesn't pay for the counting.
+func (rb *RBTree[T]) ExistsCounting(val T) (found bool, comparisons int) {
+	nd := rb.root
+
+	for nd != nil {
+		c := rb.cmp(nd.value, val)
+		comparisons++
+
+		if c == 0 {
+			return true, comparisons
+		} else if c < 0 {
+			nd = nd.right
+		} else {
+			nd = nd.left
+		}
+	}
+
+	return false, comparisons
+}
+
+// Calls fn once for each distinct value in ascending order, along with the number of nodes holding it,
+// stopping early if fn returns false. Equal values are adjacent in inorder traversal, so this is a single
+// O(n) walk that needs no extra memory. fn must not modify the tree.
+func (rb *RBTree[T]) ForEachGroup(fn func(value T, count int) bool) {
+	nd := rb.minNode()
+
+	for nd != nil {
+		first, count := nd, 0
+		for nd != nil && rb.cmp(nd.value, first.value) == 0 {
+			count++
+			nd = nd.successor()
+		}
+
+		if !fn(first.value, count) {
+			return
+		}
+	}
+}
+
+// Returns the distinct values with the number of nodes holding each, from the most to the least frequent.
+// Values with the same count are in ascending order. Returns an empty slice for an empty tree.
+// The groups are collected with ForEachGroup in O(n) time, then sorted in O(d log d) time,
+// where d is the number of distinct values.
+func (rb *RBTree[T]) ByFrequency() []struct {
+	Value T
+	Count int
+} {
+	type group = struct {
+		Value T
+		Count int
+	}
+	groups := []group{}
+
+	rb.ForEachGroup(func(value T, count int) bool {
+		groups = append(groups, group{value, count})
+		return true
+	})
+
+	// the groups are already in ascending order, which a stable sort keeps for equal counts
+	slices.SortStableFunc(groups, func(a, b group) int {
+		return b.Count - a.Count
+	})
+	return groups
+}
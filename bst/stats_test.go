@@ -0,0 +1,101 @@
+package bst
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDuplicateCounts(t *testing.T) {
+	rb := NewRBTree[int]()
+	rb.InsertAll(1, 2, 2, 3, 3, 3, 4)
+
+	if got := rb.DistinctCount(); got != 4 {
+		t.Errorf("DistinctCount: got %d", got)
+	}
+	if got := rb.DuplicateCount(); got != 3 {
+		t.Errorf("DuplicateCount: got %d", got)
+	}
+	if got := NewRBTree[int]().DuplicateCount(); got != 0 {
+		t.Errorf("DuplicateCount on an empty tree: got %d", got)
+	}
+}
+
+func TestCountFunc(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{1, 2, 2, 3, 3, 3, 4})
+
+	if got := rb.CountFunc(func(v int) bool { return v%2 == 1 }); got != 4 {
+		t.Errorf("got %d", got)
+	}
+}
+
+func TestForEachGroup(t *testing.T) {
+	rb := NewRBTreeFromSlice([]int{1, 2, 2, 3, 3, 3, 4})
+
+	var groups [][2]int
+	rb.ForEachGroup(func(v, n int) bool {
+		groups = append(groups, [2]int{v, n})
+		return v < 3
+	})
+	if want := [][2]int{{1, 1}, {2, 2}, {3, 3}}; !slices.Equal(groups, want) {
+		t.Errorf("got %v, want %v", groups, want)
+	}
+}
+
+func TestByFrequency(t *testing.T) {
+	if got := NewRBTree[int]().ByFrequency(); got == nil || len(got) != 0 {
+		t.Fatalf("empty tree: got %#v", got)
+	}
+
+	rb := NewRBTreeFromSlice([]int{5, 3, 3, 1, 5, 9, 9, 9, 2})
+	got := rb.ByFrequency()
+
+	want := []struct {
+		Value int
+		Count int
+	}{{9, 3}, {3, 2}, {5, 2}, {1, 1}, {2, 1}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestDeleteWithStats(t *testing.T) {
+	for _, cfg := range treeConfigs {
+		rb := NewRBTree[int](cfg.opts...)
+		for i := 0; i < 200; i++ {
+			rb.Insert(i)
+		}
+
+		for i := 0; i < 200; i += 2 {
+			n, err := rb.DeleteWithStats(i)
+			if err != nil || n < 0 || n > 2*rb.Height() {
+				t.Fatalf("%s: DeleteWithStats(%d): got %d, %v", cfg.name, i, n, err)
+			}
+		}
+		checkValid(t, rb)
+	}
+}
+
+func TestWouldRebalance(t *testing.T) {
+	for _, cfg := range treeConfigs {
+		rb := NewRBTree[int](cfg.opts...)
+		for i := 0; i < 200; i++ {
+			predicted := rb.WouldRebalance(i)
+			before := rb.rotations
+			rb.Insert(i)
+			if predicted != (rb.rotations != before) {
+				t.Fatalf("%s: WouldRebalance(%d) predicted %t", cfg.name, i, predicted)
+			}
+		}
+	}
+}
+
+func TestExistsCounting(t *testing.T) {
+	rb, _ := randomTree(14, 300)
+
+	for v := -1; v < 101; v++ {
+		found, n := rb.ExistsCounting(v)
+		if found != rb.Exists(v) || n < 1 || n > rb.Height() {
+			t.Fatalf("ExistsCounting(%d): got %t after %d comparisons", v, found, n)
+		}
+	}
+}
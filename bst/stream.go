@@ -0,0 +1,57 @@
+package bst
+
+import (
+	"math/bits"
+	"slices"
+)
+
+// Sends all values to ch in ascending order. Each send blocks until the value is received,
+// or until there is room in the buffer of ch, so this returns only after every value has been sent.
+// ch is not closed, since the caller owns it. The tree must not be modified until this returns.
+func (rb *RBTree[T]) WriteTo(ch chan<- T) {
+	for v := range rb.All() {
+		ch <- v
+	}
+}
+
+// Inserts every value received from ch, blocking until ch is closed. Returns once ch is closed and drained.
+// The values are buffered until then, and inserted all at once. If there are many of them compared to Len,
+// they are sorted and merged with the existing nodes into a rebuilt balanced tree in O(n + m log m) time,
+// instead of being inserted one by one in O(m log n) time. The rebuilt tree has the shape described in Rebalance.
+// Values are always inserted one by one if the tree has options that a rebuild can't honor,
+// like a duplicate policy or insertion order.
+func (rb *RBTree[T]) ReadFrom(ch <-chan T) {
+	var received []T
+	for v := range ch {
+		received = append(received, v)
+	}
+
+	n, m := rb.len, len(received)
+	if rb.dups != KeepDuplicates || rb.insertionOrder || m*bits.Len(uint(n+m)) <= n+m {
+		rb.InsertAll(received...)
+		return
+	}
+
+	slices.SortStableFunc(received, rb.cmp)
+
+	nodes := make([]*node[T], 0, n+m)
+	nd := rb.minNode()
+	for _, v := range received {
+		// Insert puts new values before equal ones, so do the same
+		for nd != nil && rb.cmp(nd.value, v) < 0 {
+			nodes = append(nodes, nd)
+			nd = nd.successor()
+		}
+		nodes = append(nodes, &node[T]{value: v})
+	}
+	for ; nd != nil; nd = nd.successor() {
+		nodes = append(nodes, nd)
+	}
+
+	rb.setRoot(linkBalanced(nodes), len(nodes))
+	rb.debugValidate()
+
+	for _, v := range received {
+		rb.notify(Inserted, v)
+	}
+}
@@ -0,0 +1,25 @@
+package bst
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWriteToReadFrom(t *testing.T) {
+	src, vals := randomTree(16, 500)
+
+	// small and large batches take different paths
+	for _, existing := range [][]int{nil, {50, 150}, slices.Repeat([]int{42}, 5000)} {
+		dst := NewRBTreeFromSlice(existing)
+
+		ch := make(chan int)
+		go func() {
+			src.WriteTo(ch)
+			close(ch)
+		}()
+		dst.ReadFrom(ch)
+
+		want := slices.Sorted(slices.Values(append(slices.Clone(existing), vals...)))
+		checkValues(t, dst, want)
+	}
+}
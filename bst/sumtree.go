@@ -0,0 +1,155 @@
+package bst
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"math/rand"
+)
+
+type sumEntry[T Number] struct {
+	value T
+	// sum of the values in the subtree rooted at the node holding this entry
+	sum T
+}
+
+// SumTree is a sorted collection of numbers that also keeps the sum of the values in every subtree,
+// which answers prefix sums and weighted sampling in O(log n) time.
+//
+// The sums are kept in the values of the underlying tree, and recomputed through its augment hook
+// on every insertion, deletion and rotation, so they are always up to date and reading them never
+// modifies the tree. Trees that don't need sums don't pay for them.
+// Floating-point sums may differ slightly from summing the values in ascending order.
+type SumTree[T Number] struct {
+	tree *RBTree[sumEntry[T]]
+}
+
+// Returns an empty SumTree. Accepts the same options as NewRBTree.
+func NewSumTree[T Number](opts ...Option) *SumTree[T] {
+	tree := NewRBTreeFunc(compareSumEntries[T], opts...)
+	tree.augment = updateSum[T]
+	return &SumTree[T]{
+		tree: tree,
+	}
+}
+
+func compareSumEntries[T Number](a, b sumEntry[T]) int {
+	return cmp.Compare(a.value, b.value)
+}
+
+// Returns the sum of the values in the subtree rooted at nd, or 0 if nd is nil.
+func sumOf[T Number](nd *node[sumEntry[T]]) T {
+	if nd == nil {
+		return 0
+	}
+	return nd.value.sum
+}
+
+// Recomputes the sum of nd from its children, whose sums must be up to date.
+func updateSum[T Number](nd *node[sumEntry[T]]) {
+	nd.value.sum = sumOf(nd.left) + nd.value.value + sumOf(nd.right)
+}
+
+func (st *SumTree[T]) Len() int {
+	return st.tree.Len()
+}
+
+// Inserts v. Like RBTree.Insert, duplicates are kept unless the tree has another duplicate policy.
+func (st *SumTree[T]) Insert(v T) {
+	// a new node is linked as a leaf, whose sum is its own value
+	st.tree.Insert(sumEntry[T]{value: v, sum: v})
+}
+
+// Deletes one copy of v. Returns an error wrapping ErrValueDoesNotExist if v is not present.
+func (st *SumTree[T]) Delete(v T) error {
+	nd := st.tree.findNode(sumEntry[T]{value: v})
+	if nd == nil {
+		return fmt.Errorf("delete %v: %w", v, ErrValueDoesNotExist)
+	}
+
+	st.tree.deleteNode(nd)
+	return nil
+}
+
+func (st *SumTree[T]) Exists(v T) bool {
+	return st.tree.findNode(sumEntry[T]{value: v}) != nil
+}
+
+// Returns the sum of all values, or 0 if the tree is empty. Takes O(1) time.
+func (st *SumTree[T]) Total() T {
+	return sumOf(st.tree.root)
+}
+
+// Returns the sum of all values <= upto, or 0 if there are none. Takes O(log n) time,
+// since each node on the search path of upto adds its own value and the sum of its left subtree at most once.
+func (st *SumTree[T]) PrefixSum(upto T) T {
+	var sum T
+	nd := st.tree.root
+
+	for nd != nil {
+		if cmp.Compare(nd.value.value, upto) <= 0 {
+			// nd and its whole left subtree are <= upto
+			sum += sumOf(nd.left) + nd.value.value
+			nd = nd.right
+		} else {
+			nd = nd.left
+		}
+	}
+
+	return sum
+}
+
+// Returns a random value using r, chosen with probability proportional to its magnitude,
+// so that a value of 3 is three times as likely as a value of 1. Each duplicate counts separately, and zeros are never chosen.
+// All values must be non-negative. Returns false if the tree is empty, or if all values are 0.
+// A random point in [0, Total) is located by descending from the root and skipping the sums of left subtrees,
+// which takes O(log n) time and doesn't modify the tree. The point is drawn as a float64, so for integer trees
+// the probabilities are only approximate once the total exceeds 2^53.
+func (st *SumTree[T]) SampleWeighted(r *rand.Rand) (T, bool) {
+	total := st.Total()
+	if total <= 0 {
+		var zero T
+		return zero, false
+	}
+
+	target := T(r.Float64() * float64(total))
+	// the last positive value passed on the way down, in case rounding leaves target beyond the total
+	var last *node[sumEntry[T]] = nil
+	nd := st.tree.root
+
+	for nd != nil {
+		leftSum := sumOf(nd.left)
+		if target < leftSum {
+			nd = nd.left
+			continue
+		}
+
+		target -= leftSum
+		if target < nd.value.value {
+			return nd.value.value, true
+		}
+
+		target -= nd.value.value
+		if nd.value.value > 0 {
+			last = nd
+		}
+		nd = nd.right
+	}
+
+	if last == nil {
+		var zero T
+		return zero, false
+	}
+	return last.value.value, true
+}
+
+// Returns an iterator over all values in ascending order.
+func (st *SumTree[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := range st.tree.All() {
+			if !yield(e.value) {
+				return
+			}
+		}
+	}
+}
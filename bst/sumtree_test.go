@@ -0,0 +1,134 @@
+package bst
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// Fails the test if the sum kept in any node differs from the sum of its subtree.
+func checkSums(t *testing.T, st *SumTree[int]) {
+	t.Helper()
+	checkValid(t, st.tree)
+
+	var walk func(nd *node[sumEntry[int]]) int
+	walk = func(nd *node[sumEntry[int]]) int {
+		if nd == nil {
+			return 0
+		}
+		s := walk(nd.left) + nd.value.value + walk(nd.right)
+		if nd.value.sum != s {
+			t.Fatalf("node %d has sum %d, want %d", nd.value.value, nd.value.sum, s)
+		}
+		return s
+	}
+	walk(st.tree.root)
+}
+
+func TestSumTree(t *testing.T) {
+	for _, cfg := range treeConfigs {
+		t.Run(cfg.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(21))
+			st := NewSumTree[int](cfg.opts...)
+			var vals []int
+
+			for i := 0; i < 2000; i++ {
+				v := r.Intn(200)
+				if r.Intn(3) > 0 {
+					st.Insert(v)
+					vals = append(vals, v)
+				} else if j := slices.Index(vals, v); j >= 0 {
+					if err := st.Delete(v); err != nil {
+						t.Fatal(err)
+					}
+					vals = slices.Delete(vals, j, j+1)
+				} else if err := st.Delete(v); !errors.Is(err, ErrValueDoesNotExist) {
+					t.Fatalf("Delete(%d) of a missing value: got error %v", v, err)
+				}
+			}
+			checkSums(t, st)
+			slices.Sort(vals)
+
+			if got := slices.Collect(st.All()); !slices.Equal(got, vals) || st.Len() != len(vals) {
+				t.Fatalf("got values %v, want %v", got, vals)
+			}
+
+			total := 0
+			for _, v := range vals {
+				total += v
+			}
+			if st.Total() != total {
+				t.Fatalf("Total: got %d, want %d", st.Total(), total)
+			}
+
+			for upto := -1; upto <= 201; upto++ {
+				want := 0
+				for _, v := range filter(vals, func(x int) bool { return x <= upto }) {
+					want += v
+				}
+				if got := st.PrefixSum(upto); got != want {
+					t.Fatalf("PrefixSum(%d): got %d, want %d", upto, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSumTreeDuplicatePolicy(t *testing.T) {
+	for _, policy := range []DuplicatePolicy{RejectDuplicates, ReplaceDuplicates} {
+		st := NewSumTree[int](WithDuplicatePolicy(policy))
+		for v := 1; v <= 5; v++ {
+			st.Insert(v)
+		}
+		st.Insert(2)
+		st.Insert(4)
+
+		checkSums(t, st)
+		if st.Len() != 5 || st.Total() != 15 {
+			t.Fatalf("policy %d: got Len %d, Total %d, want 5, 15", policy, st.Len(), st.Total())
+		}
+		if got := st.PrefixSum(3); got != 6 {
+			t.Fatalf("policy %d: PrefixSum(3): got %d, want 6", policy, got)
+		}
+	}
+}
+
+func TestSumTreeSampleWeighted(t *testing.T) {
+	r := rand.New(rand.NewSource(22))
+	st := NewSumTree[int]()
+
+	if _, ok := st.SampleWeighted(r); ok {
+		t.Fatal("sampled from an empty tree")
+	}
+	st.Insert(0)
+	if _, ok := st.SampleWeighted(r); ok {
+		t.Fatal("sampled from a tree of zeros")
+	}
+
+	// 0 is never chosen, and 3 should come up about as often as both copies of 1 and 2 combined
+	st.Insert(1)
+	st.Insert(1)
+	st.Insert(2)
+	st.Insert(3)
+	counts := map[int]int{}
+	const n = 80000
+	for i := 0; i < n; i++ {
+		v, ok := st.SampleWeighted(r)
+		if !ok {
+			t.Fatal("no value sampled from a non-empty tree")
+		}
+		counts[v]++
+	}
+
+	if counts[0] != 0 {
+		t.Fatalf("0 was chosen %d times", counts[0])
+	}
+	for v, weight := range map[int]int{1: 2, 2: 2, 3: 3} {
+		// each value should get weight/7 of the draws, within a few percent
+		want := n * weight / 7
+		if got := counts[v]; got < want*95/100 || got > want*105/100 {
+			t.Errorf("%d was chosen %d times, want about %d", v, got, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package bst
+
+import (
+	"cmp"
+	"iter"
+	"sync"
+)
+
+// SyncRBTree wraps RBTree with a read-write mutex, making it safe for concurrent use.
+// Reads can proceed in parallel, while writes are exclusive.
+type SyncRBTree[T cmp.Ordered] struct {
+	mu   sync.RWMutex
+	tree *RBTree[T]
+}
+
+func NewSyncRBTree[T cmp.Ordered](opts ...Option) *SyncRBTree[T] {
+	return &SyncRBTree[T]{
+		tree: NewRBTree[T](opts...),
+	}
+}
+
+func (st *SyncRBTree[T]) Len() int {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.tree.Len()
+}
+
+// Insert a new node in the tree with the given value. Inserts even if the value already exists.
+func (st *SyncRBTree[T]) Insert(val T) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.tree.Insert(val)
+}
+
+// Returns true if there exists a node having the given value in the tree.
+func (st *SyncRBTree[T]) Exists(val T) bool {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.tree.Exists(val)
+}
+
+// Deletes a node in the tree with the given value. See RBTree.Delete.
+func (st *SyncRBTree[T]) Delete(val T) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.tree.Delete(val)
+}
+
+// Returns the values of nodes in ascending order.
+func (st *SyncRBTree[T]) GetValues() []T {
+	// GetValues temporarily threads the tree during Morris traversal, so it needs the write lock.
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.tree.GetValues()
+}
+
+// Returns an iterator over a snapshot of the values in ascending order.
+// The read lock is held only while the values are copied, not while they are consumed,
+// so a slow consumer does not block writers. Changes made after the call are not reflected.
+// The snapshot costs O(n) time and memory, where n is the length of the tree at the time of the call.
+func (st *SyncRBTree[T]) SnapshotIter() iter.Seq[T] {
+	st.mu.RLock()
+	values := make([]T, 0, st.tree.Len())
+	for nd := st.tree.minNode(); nd != nil; nd = nd.successor() {
+		values = append(values, nd.value)
+	}
+	st.mu.RUnlock()
+
+	return func(yield func(T) bool) {
+		for _, v := range values {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package bst
+
+import "iter"
+
+// Walks the tree in preorder, calling visit on each node's value.
+// The flags returned by visit decide whether the left and right subtrees of that node are walked.
+// Since left subtrees hold smaller values and right subtrees larger ones, this can skip entire ranges,
+// e.g. stop going right once values exceed a bound.
+func (rb *RBTree[T]) WalkPrune(visit func(T) (recurseLeft, recurseRight bool)) {
+	if rb.root == nil {
+		return
+	}
+
+	stack := []*node[T]{rb.root}
+
+	for len(stack) > 0 {
+		nd := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		recurseLeft, recurseRight := visit(nd.value)
+
+		// push right first so that left subtree is walked first
+		if recurseRight && nd.right != nil {
+			stack = append(stack, nd.right)
+		}
+		if recurseLeft && nd.left != nil {
+			stack = append(stack, nd.left)
+		}
+	}
+}
+
+// Returns an iterator over values in preorder: node, then left subtree, then right subtree.
+// Like the other traversals in this file, it uses an explicit stack on the heap rather than recursion,
+// so it never grows the goroutine stack, even for a tall tree left by InsertNoFixup.
+func (rb *RBTree[T]) Preorder() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if rb.root == nil {
+			return
+		}
+
+		gen := rb.gen
+
+		stack := []*node[T]{rb.root}
+
+		for len(stack) > 0 {
+			nd := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			if !yield(nd.value) {
+				return
+			}
+			rb.checkGen(gen)
+
+			// push right first so that left subtree is visited first
+			if nd.right != nil {
+				stack = append(stack, nd.right)
+			}
+			if nd.left != nil {
+				stack = append(stack, nd.left)
+			}
+		}
+	}
+}
+
+// Returns an iterator over values in postorder: left subtree, then right subtree, then node.
+// Uses an explicit stack rather than recursion.
+func (rb *RBTree[T]) Postorder() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		gen := rb.gen
+		var stack []*node[T]
+		var last *node[T] = nil
+		nd := rb.root
+
+		for nd != nil || len(stack) > 0 {
+			// go as far left as possible
+			for nd != nil {
+				stack = append(stack, nd)
+				nd = nd.left
+			}
+
+			top := stack[len(stack)-1]
+			if top.right != nil && top.right != last {
+				// right subtree hasn't been visited yet
+				nd = top.right
+				continue
+			}
+
+			if !yield(top.value) {
+				return
+			}
+			rb.checkGen(gen)
+			last = top
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// Returns an iterator over values level by level from the root, left to right within each level.
+// Uses an explicit queue rather than recursion.
+func (rb *RBTree[T]) LevelOrder() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if rb.root == nil {
+			return
+		}
+
+		gen := rb.gen
+
+		queue := []*node[T]{rb.root}
+
+		for len(queue) > 0 {
+			nd := queue[0]
+			queue = queue[1:]
+
+			if !yield(nd.value) {
+				return
+			}
+			rb.checkGen(gen)
+
+			if nd.left != nil {
+				queue = append(queue, nd.left)
+			}
+			if nd.right != nil {
+				queue = append(queue, nd.right)
+			}
+		}
+	}
+}
+
+// Returns the values of the tree level by level, along with their colors.
+// Level i holds the nodes at depth i from left to right, and the root is at depth 0.
+// Returns nil for an empty tree. Takes O(n) time.
+func (rb *RBTree[T]) LevelOrderColored() [][]struct {
+	Value T
+	Red   bool
+} {
+	var levels [][]struct {
+		Value T
+		Red   bool
+	}
+
+	var level []*node[T]
+	if rb.root != nil {
+		level = []*node[T]{rb.root}
+	}
+
+	for len(level) > 0 {
+		row := make([]struct {
+			Value T
+			Red   bool
+		}, len(level))
+		var next []*node[T]
+
+		for i, nd := range level {
+			row[i].Value = nd.value
+			row[i].Red = nd.clr == red
+
+			if nd.left != nil {
+				next = append(next, nd.left)
+			}
+			if nd.right != nil {
+				next = append(next, nd.right)
+			}
+		}
+
+		levels = append(levels, row)
+		level = next
+	}
+
+	return levels
+}
+
+// Visitor receives the nodes of a tree in depth-first order from Accept.
+// Enter is called when the subtree of a node is entered, before its left and right subtrees,
+// and Exit once both have been visited. The root is at depth 0.
+type Visitor[T any] interface {
+	Enter(value T, depth int)
+	Exit(value T, depth int)
+}
+
+// Walks the tree depth-first, left subtree before right, calling v.Enter and v.Exit around each subtree.
+// The calls are properly nested, so v can build nested representations, like an indented printout.
+// Uses an explicit stack rather than recursion. v must not modify the tree. Takes O(n) time.
+func (rb *RBTree[T]) Accept(v Visitor[T]) {
+	if rb.root == nil {
+		return
+	}
+
+	type item struct {
+		nd      *node[T]
+		depth   int
+		entered bool
+	}
+
+	gen := rb.gen
+	stack := []item{{rb.root, 0, false}}
+
+	for len(stack) > 0 {
+		it := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if it.entered {
+			v.Exit(it.nd.value, it.depth)
+			rb.checkGen(gen)
+			continue
+		}
+
+		v.Enter(it.nd.value, it.depth)
+		rb.checkGen(gen)
+
+		// the exit is popped only after both subtrees, and the right subtree after the left one
+		stack = append(stack, item{it.nd, it.depth, true})
+		if it.nd.right != nil {
+			stack = append(stack, item{it.nd.right, it.depth + 1, false})
+		}
+		if it.nd.left != nil {
+			stack = append(stack, item{it.nd.left, it.depth + 1, false})
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package bst
+
+import "fmt"
+
+// Validate checks that the tree satisfies all the red-black properties listed on RBTree,
+// along with the binary search ordering, parent links, the node count and the cached minimum and maximum.
+// With AVL balancing, the AVL property is checked instead of the red-black properties.
+// Returns nil if the tree is valid. Otherwise, the returned error describes the first violation found.
+func (rb *RBTree[T]) Validate() error {
+	if rb.root == nil {
+		if rb.len != 0 {
+			return fmt.Errorf("tree is empty but len is %d", rb.len)
+		}
+		if rb.minNd != nil || rb.maxNd != nil {
+			return fmt.Errorf("tree is empty but has cached minimum or maximum")
+		}
+		return nil
+	}
+
+	if mn := rb.root.getMin(); rb.minNd != mn {
+		return fmt.Errorf("minimum is %v but the cached minimum is not that node", mn.value)
+	}
+	if mx := rb.root.getMax(); rb.maxNd != mx {
+		return fmt.Errorf("maximum is %v but the cached maximum is not that node", mx.value)
+	}
+
+	if rb.root.parent != nil {
+		return fmt.Errorf("root %v has non-nil parent", rb.root.value)
+	}
+
+	redBlack := rb.balancing == RedBlack
+
+	if redBlack && rb.root.color() != black {
+		return fmt.Errorf("root %v is not black", rb.root.value)
+	}
+
+	// Walk in preorder with an explicit stack, so that even a corrupted, very tall tree can't overflow the goroutine stack.
+	// Property 5 holds in every subtree if and only if all paths from the root to nil leaves contain the same number of black nodes.
+	type frame struct {
+		nd *node[T]
+		// all values in the subtree of nd must lie within the values of lo and hi, which are nil when unbounded
+		lo, hi *node[T]
+		// number of black nodes from root to nd, including nd
+		blacks int
+	}
+
+	stack := []frame{{nd: rb.root, blacks: 1}}
+	count := 0
+	leafBlacks := -1
+
+	for len(stack) > 0 {
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		nd := f.nd
+		count++
+
+		if f.lo != nil && rb.cmp(nd.value, f.lo.value) < 0 {
+			return fmt.Errorf("node %v is less than its ancestor %v", nd.value, f.lo.value)
+		}
+		if f.hi != nil && rb.cmp(nd.value, f.hi.value) > 0 {
+			return fmt.Errorf("node %v is greater than its ancestor %v", nd.value, f.hi.value)
+		}
+
+		if nd.sz != nd.left.size()+nd.right.size()+1 {
+			return fmt.Errorf("node %v has size %d but its subtree has %d nodes", nd.value, nd.sz, nd.left.size()+nd.right.size()+1)
+		}
+
+		if nd.ht != max(nd.left.height(), nd.right.height())+1 {
+			return fmt.Errorf("node %v has height %d but its subtree is %d high", nd.value, nd.ht, max(nd.left.height(), nd.right.height())+1)
+		}
+
+		if !redBlack {
+			if bf := nd.left.height() - nd.right.height(); bf > 1 || bf < -1 {
+				return fmt.Errorf("subtrees of node %v differ in height by %d", nd.value, bf)
+			}
+		}
+
+		for _, child := range [2]*node[T]{nd.left, nd.right} {
+			if child == nil {
+				if !redBlack {
+					continue
+				}
+
+				if leafBlacks == -1 {
+					leafBlacks = f.blacks
+				} else if leafBlacks != f.blacks {
+					return fmt.Errorf("paths through %v have %d black nodes, but other paths have %d", nd.value, f.blacks, leafBlacks)
+				}
+				continue
+			}
+
+			if child.parent != nd {
+				return fmt.Errorf("child %v of %v has wrong parent", child.value, nd.value)
+			}
+			if redBlack && nd.color() == red && child.color() == red {
+				return fmt.Errorf("red node %v has red child %v", nd.value, child.value)
+			}
+
+			blacks := f.blacks
+			if child.color() == black {
+				blacks++
+			}
+
+			if child == nd.left {
+				stack = append(stack, frame{nd: child, lo: f.lo, hi: nd, blacks: blacks})
+			} else {
+				stack = append(stack, frame{nd: child, lo: nd, hi: f.hi, blacks: blacks})
+			}
+		}
+
+		if count > rb.len {
+			// also guards against cycles in a corrupted tree
+			return fmt.Errorf("tree has more nodes than its len %d", rb.len)
+		}
+	}
+
+	if count != rb.len {
+		return fmt.Errorf("tree has %d nodes but len is %d", count, rb.len)
+	}
+
+	return nil
+}
+
+// Checks only the parent links: the root must have a nil parent, and every child must point back to its parent.
+// Unlike Validate, it ignores ordering and colors, so it isolates bugs in link manipulation during rotations,
+// which would otherwise break parent-based stepping like Successor and iterators. Takes O(n) time.
+func (rb *RBTree[T]) CheckParents() error {
+	if rb.root == nil {
+		return nil
+	}
+
+	if rb.root.parent != nil {
+		return fmt.Errorf("root %v has non-nil parent", rb.root.value)
+	}
+
+	// walk using child links only, since parent links are under test
+	stack := []*node[T]{rb.root}
+
+	for len(stack) > 0 {
+		nd := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if nd.left != nil {
+			if nd.left.parent != nd {
+				return fmt.Errorf("left child %v of %v has wrong parent", nd.left.value, nd.value)
+			}
+			stack = append(stack, nd.left)
+		}
+
+		if nd.right != nil {
+			if nd.right.parent != nd {
+				return fmt.Errorf("right child %v of %v has wrong parent", nd.right.value, nd.value)
+			}
+			stack = append(stack, nd.right)
+		}
+	}
+
+	return nil
+}
+
+// Returns true if an inorder walk yields values in non-decreasing order according to the tree's comparator.
+// Unlike Validate, it ignores colors and structure, so it isolates ordering bugs such as an inconsistent comparator.
+// Takes O(n) time.
+func (rb *RBTree[T]) IsSorted() bool {
+	if rb.root == nil {
+		return true
+	}
+
+	var prev *node[T] = nil
+	// the walk starts from the root rather than the cached minimum, which might be stale in a corrupted tree
+	for nd := rb.root.getMin(); nd != nil; nd = nd.successor() {
+		if prev != nil && rb.cmp(prev.value, nd.value) > 0 {
+			return false
+		}
+		prev = nd
+	}
+	return true
+}
+
+// Returns the values of nodes in ascending order, like GetValues, but stepping from node to node
+// through parent links instead of threading the tree like the Morris traversal of GetValues.
+// Meant for tests: the two results differ only if parent links are corrupted or the Morris traversal is broken.
+// Takes O(n) time.
+func (rb *RBTree[T]) GetValuesViaParents() []T {
+	values := make([]T, 0, rb.len)
+	if rb.root == nil {
+		return values
+	}
+
+	// like IsSorted, the walk starts from the root rather than the cached minimum
+	for nd := rb.root.getMin(); nd != nil; nd = nd.successor() {
+		values = append(values, nd.value)
+	}
+	return values
+}
+
+// Returns the number of the first red-black property listed on RBTree that the tree violates,
+// along with the value of the offending node, or false if all properties hold.
+// Properties are checked in the order they are numbered, and the node reported for each is:
+//   - 1: the first node in inorder with a color other than red or black.
+//   - 2: the root.
+//   - 4: the first red node in inorder having a red child.
+//   - 5: the first node in postorder whose subtrees have different black heights.
+//     Its own subtrees satisfy the property, so the imbalance was introduced at this node.
+//
+// Property 3 always holds, since nil leaves are black by definition. Unlike Validate, ordering,
+// sizes and parent links are not checked. Only meaningful with red-black balancing. Takes O(n) time and memory.
+func (rb *RBTree[T]) FirstViolation() (property int, value T, ok bool) {
+	if rb.root == nil {
+		return 0, value, false
+	}
+
+	var badColor, redRed *node[T]
+	var stack []*node[T]
+
+	// inorder walk using child links only
+	for nd := rb.root; nd != nil || len(stack) > 0; {
+		for nd != nil {
+			stack = append(stack, nd)
+			nd = nd.left
+		}
+
+		nd = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if badColor == nil && nd.clr != red && nd.clr != black {
+			badColor = nd
+		}
+		if redRed == nil && nd.clr == red && (nd.left.color() == red || nd.right.color() == red) {
+			redRed = nd
+		}
+
+		nd = nd.right
+	}
+
+	if badColor != nil {
+		return 1, badColor.value, true
+	}
+	if rb.root.clr != black {
+		return 2, rb.root.value, true
+	}
+	if redRed != nil {
+		return 4, redRed.value, true
+	}
+
+	// black heights of visited subtrees, not counting the nil leaves, which are missing from the map
+	blackHeights := make(map[*node[T]]int, rb.len)
+	var last *node[T] = nil
+
+	// postorder walk, like Postorder
+	for nd := rb.root; nd != nil || len(stack) > 0; {
+		for nd != nil {
+			stack = append(stack, nd)
+			nd = nd.left
+		}
+
+		top := stack[len(stack)-1]
+		if top.right != nil && top.right != last {
+			nd = top.right
+			continue
+		}
+
+		l, r := blackHeights[top.left], blackHeights[top.right]
+		if l != r {
+			return 5, top.value, true
+		}
+		if top.clr == black {
+			l++
+		}
+		blackHeights[top] = l
+
+		last = top
+		stack = stack[:len(stack)-1]
+	}
+
+	return 0, value, false
+}
+
+// Returns true if the tree is a valid binary search tree, ignoring colors and balance:
+// parent links must be consistent, and an inorder walk must yield values in non-decreasing order,
+// which holds if and only if every left descendant of a node is <= it, and every right descendant is >= it.
+// Combines CheckParents and IsSorted, checking parent links first since the inorder walk relies on them.
+// Takes O(n) time.
+func (rb *RBTree[T]) IsValidBST() bool {
+	return rb.CheckParents() == nil && rb.IsSorted()
+}
+
+// Counts the nodes of the tree, sets Len to the count, and returns it.
+// Meant as a defensive recovery if Len ever disagrees with the actual number of nodes, which Validate reports.
+// Nodes are counted by walking child links, without relying on the cached subtree sizes. Takes O(n) time.
+func (rb *RBTree[T]) RecountLen() int {
+	count := 0
+	var stack []*node[T]
+	if rb.root != nil {
+		stack = append(stack, rb.root)
+	}
+
+	for len(stack) > 0 {
+		nd := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		count++
+
+		if nd.left != nil {
+			stack = append(stack, nd.left)
+		}
+		if nd.right != nil {
+			stack = append(stack, nd.right)
+		}
+	}
+
+	rb.len = count
+	return count
+}
@@ -0,0 +1,136 @@
+package bst
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestValidateDetectsCorruption(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(rb *RBTree[int])
+	}{
+		{"red root", func(rb *RBTree[int]) { rb.root.clr = red }},
+		{"wrong len", func(rb *RBTree[int]) { rb.len++ }},
+		{"wrong size", func(rb *RBTree[int]) { rb.root.left.sz++ }},
+		{"wrong height", func(rb *RBTree[int]) { rb.root.ht++ }},
+		{"wrong parent", func(rb *RBTree[int]) { rb.root.left.parent = rb.root.right }},
+		{"out of order", func(rb *RBTree[int]) { rb.root.left.value = 100 }},
+		{"stale minimum", func(rb *RBTree[int]) { rb.minNd = rb.root }},
+	}
+
+	for _, tt := range tests {
+		rb := NewRBTreeFromSlice([]int{1, 2, 3, 4, 5, 6, 7})
+		checkValid(t, rb)
+		tt.corrupt(rb)
+		if rb.Validate() == nil {
+			t.Errorf("%s: not detected", tt.name)
+		}
+	}
+}
+
+func TestFirstViolation(t *testing.T) {
+	//       4
+	//     /   \
+	//    2     6
+	//   / \   / \
+	//  1   3 5   7   (red)
+	rb := NewRBTreeFromSlice([]int{1, 2, 3, 4, 5, 6, 7})
+	if p, _, ok := rb.FirstViolation(); ok {
+		t.Fatalf("valid tree violates property %d", p)
+	}
+
+	rb.root.left.clr = red
+	if p, v, ok := rb.FirstViolation(); !ok || p != 4 || v != 2 {
+		t.Fatalf("red node with red children: got %d, %d, %t", p, v, ok)
+	}
+
+	rb.root.left.clr = black
+	rb.root.left.left.clr = black
+	if p, v, ok := rb.FirstViolation(); !ok || p != 5 || v != 2 {
+		t.Fatalf("unequal black heights: got %d, %d, %t", p, v, ok)
+	}
+
+	rb.root.clr = red
+	if p, v, ok := rb.FirstViolation(); !ok || p != 2 || v != 4 {
+		t.Fatalf("red root: got %d, %d, %t", p, v, ok)
+	}
+}
+
+func TestCheckParents(t *testing.T) {
+	rb, _ := randomTree(15, 100)
+	if err := rb.CheckParents(); err != nil {
+		t.Fatal(err)
+	}
+
+	rb.root.right.parent = nil
+	if rb.CheckParents() == nil {
+		t.Fatal("broken parent link not detected")
+	}
+}
+
+func TestIsSorted(t *testing.T) {
+	rb, _ := randomTree(15, 100)
+	if !rb.IsSorted() {
+		t.Fatal("valid tree reported as unsorted")
+	}
+
+	old := rb.root.left.value
+	rb.root.left.value = 1000
+	if rb.IsSorted() {
+		t.Fatal("out-of-order value not detected")
+	}
+	rb.root.left.value = old
+	if !rb.IsSorted() {
+		t.Fatal("restored tree reported as unsorted")
+	}
+
+	// a stale cached minimum must not hide an out-of-order first value
+	first := rb.minNd
+	first.value = 1000
+	rb.minNd = first.successor()
+	if rb.IsSorted() {
+		t.Fatal("out-of-order first value not detected")
+	}
+
+	if !NewRBTree[int]().IsSorted() {
+		t.Fatal("empty tree reported as unsorted")
+	}
+}
+
+func TestGetValuesViaParents(t *testing.T) {
+	rb, vals := randomTree(15, 100)
+	if got := rb.GetValuesViaParents(); !slices.Equal(got, vals) {
+		t.Fatalf("got %v, want %v", got, vals)
+	}
+
+	// a stale cached minimum must not drop the first values
+	rb.minNd = rb.minNd.successor().successor()
+	if got := rb.GetValuesViaParents(); !slices.Equal(got, vals) {
+		t.Fatalf("with a stale minimum: got %v, want %v", got, vals)
+	}
+	if got := NewRBTree[int]().GetValuesViaParents(); len(got) != 0 {
+		t.Fatalf("empty tree: got %v", got)
+	}
+}
+
+func TestIsValidBST(t *testing.T) {
+	rb, _ := randomTree(15, 100)
+	if !rb.IsValidBST() {
+		t.Fatal("valid tree reported as invalid")
+	}
+
+	rb.root.right.parent = nil
+	if rb.IsValidBST() {
+		t.Fatal("broken parent link not detected")
+	}
+}
+
+func TestRecountLen(t *testing.T) {
+	rb, vals := randomTree(15, 100)
+
+	rb.len = 3
+	if got := rb.RecountLen(); got != len(vals) || rb.Len() != len(vals) {
+		t.Fatalf("got %d, want %d", got, len(vals))
+	}
+}